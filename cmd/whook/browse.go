@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/lukeberry99/whook/internal/bundle"
+	"github.com/lukeberry99/whook/internal/config"
+	"github.com/lukeberry99/whook/internal/storage"
+	"github.com/lukeberry99/whook/internal/ui"
+)
+
+// runBrowse opens the UI over a storage directory or bundle without starting
+// the server, a tunnel or the scheduler
+func runBrowse(args []string) error {
+	flags := flag.NewFlagSet("browse", flag.ExitOnError)
+	session := flags.String("session", "", "Session to browse instead of the default one")
+	dir := flags.String("dir", "", "Directory to open a bundle into (defaults to a directory per bundle under ~/.local/share/whook/bundles)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: whook browse [flags] [storage directory|bundle]")
+		fmt.Fprintln(flags.Output(), "Browses the configured storage directory when no path is given.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return fmt.Errorf("expected at most one path")
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+
+	path := cfg.Storage.Path
+	if flags.NArg() == 1 {
+		path = flags.Arg(0)
+	}
+	if path == "" {
+		return browse(cfg, "", *session, "the storage directory")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", path, err)
+	}
+
+	var opened *bundle.Opened
+	switch {
+	case !info.IsDir():
+		if *dir == "" {
+			*dir = bundle.DefaultDir(path)
+		}
+		opened, err = bundle.Open(path, *dir)
+	case bundle.IsExtracted(path):
+		// A bundle that was already opened, or extracted by hand
+		opened, err = bundle.Load(path)
+	default:
+		return browse(cfg, path, *session, path)
+	}
+	if err != nil {
+		return err
+	}
+
+	opened.MergeServices(cfg)
+
+	return browse(cfg, opened.StorePath(), *session, "bundle "+path)
+}
+
+// browse starts the UI over a read-only view of the storage at path
+func browse(cfg *config.Config, path, session, name string) error {
+	store, err := storage.OpenReadOnly(path)
+	if err != nil {
+		return err
+	}
+	if err := store.SetSession(session); err != nil {
+		return err
+	}
+	cfg.Storage.Path = store.Root()
+
+	logChan := make(chan string, 100)
+	logChan <- fmt.Sprintf("Browsing %s (read-only), no server or tunnel is running", name)
+
+	return ui.StartUI(cfg, logChan, store, nil, nil)
+}
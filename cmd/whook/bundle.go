@@ -0,0 +1,135 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lukeberry99/whook/internal/bundle"
+	"github.com/lukeberry99/whook/internal/config"
+)
+
+func runBundle(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "create":
+			return runBundleCreate(args[1:])
+		case "open":
+			return runBundleOpen(args[1:])
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "Usage: whook bundle create|open [flags]")
+	return fmt.Errorf("unknown bundle command")
+}
+
+func runBundleCreate(args []string) error {
+	flags := flag.NewFlagSet("bundle create", flag.ExitOnError)
+	selection := addSelectionFlags(flags)
+	out := flags.String("out", "", "Bundle file to write (defaults to whook-bundle-<time>.tar.gz)")
+	notes := flags.String("notes", "", "Notes for whoever opens the bundle")
+	notesFile := flags.String("notes-file", "", "File to read the notes from, such as a markdown write-up")
+	keepSignatures := flags.Bool("keep-signatures", false, "Keep signature headers so the events can be verified by whoever has the secret")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+
+	store, err := selection.openStore(cfg)
+	if err != nil {
+		return err
+	}
+
+	matches, err := selection.run(cfg, store)
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("no events matched the selection")
+	}
+
+	opts := bundle.CreateOptions{
+		Notes:          *notes,
+		Query:          *selection.expr,
+		KeepSignatures: *keepSignatures,
+	}
+	if *notesFile != "" {
+		data, err := os.ReadFile(*notesFile)
+		if err != nil {
+			return fmt.Errorf("reading notes: %w", err)
+		}
+		opts.Notes = string(data)
+	}
+
+	path := *out
+	if path == "" {
+		path = fmt.Sprintf("whook-bundle-%s.tar.gz", time.Now().Format("20060102-150405"))
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", path, err)
+	}
+	defer f.Close()
+
+	manifest, err := bundle.Create(f, store, matches, cfg, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Bundled %d events into %s\n", len(manifest.Events), path)
+	return nil
+}
+
+func runBundleOpen(args []string) error {
+	flags := flag.NewFlagSet("bundle open", flag.ExitOnError)
+	dir := flags.String("dir", "", "Directory to open the bundle into (defaults to a directory per bundle under ~/.local/share/whook/bundles)")
+	noUI := flags.Bool("no-ui", false, "Only open the bundle and print its summary")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: whook bundle open [flags] <bundle>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected a bundle file")
+	}
+
+	bundlePath := flags.Arg(0)
+	if *dir == "" {
+		*dir = bundle.DefaultDir(bundlePath)
+	}
+
+	opened, err := bundle.Open(bundlePath, *dir)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Opened %d events from %s into %s (read-only)\n", len(opened.Manifest.Events), bundlePath, opened.StorePath())
+	fmt.Printf("Created %s with whook %s\n", opened.Manifest.CreatedAt.Format(time.RFC3339), opened.Manifest.WhookVersion)
+	if opened.Manifest.Query != "" {
+		fmt.Printf("Selected with: %s\n", opened.Manifest.Query)
+	}
+	if opened.Notes != "" {
+		fmt.Printf("\n%s\n", opened.Notes)
+	}
+
+	if *noUI {
+		return nil
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+	opened.MergeServices(cfg)
+
+	return browse(cfg, opened.StorePath(), "", "bundle "+bundlePath)
+}
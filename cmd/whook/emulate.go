@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lukeberry99/whook/internal/config"
+	"github.com/lukeberry99/whook/internal/emulator"
+	"github.com/lukeberry99/whook/internal/replay"
+	"github.com/lukeberry99/whook/internal/signing"
+	"github.com/lukeberry99/whook/internal/storage"
+	"github.com/lukeberry99/whook/internal/transform"
+)
+
+func runEmulate(args []string) error {
+	flags := flag.NewFlagSet("emulate", flag.ExitOnError)
+	provider := flags.String("provider", "chargebee", fmt.Sprintf("Provider to behave like, one of %s", strings.Join(emulator.ProfileNames(), ", ")))
+	target := flags.String("to", "", "URL to deliver the events to (defaults to the service's replay_url)")
+	serviceName := flags.String("service", "", "Service whose signing secrets and transforms should be used")
+	speed := flags.Float64("speed", 1, "Divide the provider's retry delays by this factor, 60 turns hours into minutes")
+	secret := flags.String("secret", "", "Signing secret, overrides the service's configuration")
+	username := flags.String("username", "", "Basic auth username for chargebee, overrides the service's configuration")
+	password := flags.String("password", "", "Basic auth password for chargebee, overrides the service's configuration")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: whook emulate [flags] <event file>...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("expected at least one event file")
+	}
+
+	profile, err := emulator.LookupProfile(*provider)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+
+	service := cfg.Services[*serviceName]
+	if *target == "" {
+		*target = service.ReplayURL
+	}
+	if *target == "" {
+		return fmt.Errorf("no target given, use --to or set replay_url for the service")
+	}
+
+	// The provider decides how requests are signed, the service only
+	// supplies the secrets
+	signingConfig := signing.Config{
+		Scheme:   profile.Scheme,
+		Secret:   firstNonEmpty(*secret, service.Signing.Secret),
+		Username: firstNonEmpty(*username, service.Signing.Username),
+		Password: firstNonEmpty(*password, service.Signing.Password),
+	}
+	// Chargebee's basic auth is optional, without a username it delivers
+	// unsigned
+	if signingConfig.Scheme == signing.SchemeChargebee && signingConfig.Username == "" {
+		signingConfig.Scheme = signing.SchemeNone
+	}
+	signer, err := signing.New(signingConfig)
+	if err != nil {
+		return fmt.Errorf("configuring %s signing: %w", profile.Name, err)
+	}
+
+	pipeline, err := transform.New(service.Transforms)
+	if err != nil {
+		return fmt.Errorf("configuring transforms: %w", err)
+	}
+
+	store, err := storage.NewFileStorage(cfg.Storage.Path)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+
+	em := emulator.New(emulator.Config{
+		Profile: profile,
+		Target:  *target,
+		Request: replay.Request{Signer: signer, Transform: pipeline},
+		Speed:   *speed,
+		OnAttempt: func(attempt storage.Attempt) {
+			outcome := fmt.Sprintf("%d", attempt.StatusCode)
+			if attempt.Error != "" {
+				outcome = attempt.Error
+			}
+			fmt.Printf("%s attempt %d: %s in %s\n", attempt.Event, attempt.Number, outcome, attempt.Duration.Round(time.Millisecond))
+		},
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	for _, path := range flags.Args() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading event file: %w", err)
+		}
+
+		body, err := storage.ExtractEventBody(data)
+		if err != nil {
+			return err
+		}
+
+		// Attempts are filed beside each event, in whichever session it's in
+		delivered, err := em.Deliver(ctx, emulator.Delivery{
+			Event:    filepath.Base(path),
+			Body:     body,
+			Recorder: store.ForEvent(path),
+		})
+		if err != nil {
+			return fmt.Errorf("delivering %s: %w", path, err)
+		}
+		if !delivered {
+			fmt.Printf("%s: gave up after exhausting %s's retries\n", filepath.Base(path), profile.Name)
+		}
+		if em.Disabled() {
+			return fmt.Errorf("%s disabled the endpoint after too many consecutive failures", profile.Name)
+		}
+	}
+
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
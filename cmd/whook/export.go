@@ -0,0 +1,134 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/lukeberry99/whook/internal/config"
+	"github.com/lukeberry99/whook/internal/export"
+	"github.com/lukeberry99/whook/internal/jsonpath"
+	"github.com/lukeberry99/whook/internal/query"
+)
+
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	selection := addSelectionFlags(flags)
+	format := flags.String("format", export.FormatHAR, "Export format: "+strings.Join(export.Formats(), ", "))
+	out := flags.String("out", "", "File to write the export to (defaults to stdout)")
+	baseURL := flags.String("base-url", "", "URL the captured paths are relative to (defaults to http://localhost:<port>)")
+	name := flags.String("name", "whook export", "Name of the exported Postman collection")
+	columnsFlag := flags.String("columns", "", "csv and tsv columns: fields, header:<name> and $ JSON paths separated by commas, or the name of a saved column set")
+	save := flags.String("save", "", "Save --columns as a named column set for --service")
+	keepSecrets := flags.Bool("keep-secrets", false, "Export credentials such as Authorization and the captured signatures instead of redacting them")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: whook export [har|curl|postman|jsonl|csv|tsv] [flags]")
+		flags.PrintDefaults()
+	}
+	// The format can also be given first, as in whook export csv --columns ...
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		*format = args[0]
+		args = args[1:]
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+
+	store, err := selection.openStore(cfg)
+	if err != nil {
+		return err
+	}
+
+	matches, err := selection.run(cfg, store)
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("no events matched the selection")
+	}
+
+	columns, err := resolveColumns(cfg, *columnsFlag, *selection.service, matches)
+	if err != nil {
+		return err
+	}
+
+	if *save != "" {
+		if *selection.service == "" {
+			return fmt.Errorf("--save needs --service to save the columns under")
+		}
+		if *columnsFlag == "" {
+			return fmt.Errorf("--save needs --columns")
+		}
+		if err := config.Set("", []string{"services", *selection.service, "columns", *save}, columns); err != nil {
+			return fmt.Errorf("saving columns: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Saved column set %s for %s\n", *save, *selection.service)
+	}
+
+	opts := export.Options{
+		BaseURL:          *baseURL,
+		Name:             *name,
+		Columns:          columns,
+		SignatureHeaders: cfg.SignatureHeaders(),
+		KeepSecrets:      *keepSecrets,
+	}
+	if opts.BaseURL == "" {
+		opts.BaseURL = fmt.Sprintf("http://localhost:%d", cfg.Server.Port)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return fmt.Errorf("creating %s: %w", *out, err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if err := export.Write(w, *format, matches, opts); err != nil {
+		return err
+	}
+
+	if *out != "" {
+		fmt.Fprintf(os.Stderr, "Exported %d events to %s\n", len(matches), *out)
+	}
+
+	return nil
+}
+
+// resolveColumns expands a column set saved for the exported service, a list
+// of columns is used as is. Without --columns the service's "default" set is
+// used when there is one.
+func resolveColumns(cfg *config.Config, spec, service string, matches []query.Match) ([]string, error) {
+	// Saved sets can still be used when every match is from one service
+	if service == "" && len(matches) > 0 {
+		service = matches[0].Item.ServiceName
+		for _, match := range matches[1:] {
+			if match.Item.ServiceName != service {
+				service = ""
+				break
+			}
+		}
+	}
+	sets := cfg.Services[service].Columns
+
+	if spec == "" {
+		return sets["default"], nil
+	}
+
+	if !strings.Contains(spec, ",") && !jsonpath.IsPath(spec) {
+		if columns, ok := sets[spec]; ok {
+			return columns, nil
+		}
+	}
+
+	return export.ParseColumns(spec), nil
+}
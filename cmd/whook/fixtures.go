@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lukeberry99/whook/internal/config"
+	"github.com/lukeberry99/whook/internal/fixtures"
+	"github.com/lukeberry99/whook/internal/replay"
+	"github.com/lukeberry99/whook/internal/signing"
+)
+
+func runFixtures(args []string) error {
+	if len(args) == 0 || args[0] != "export" {
+		fmt.Fprintln(os.Stderr, "Usage: whook fixtures export [flags]")
+		return fmt.Errorf("unknown fixtures command")
+	}
+
+	flags := flag.NewFlagSet("fixtures export", flag.ExitOnError)
+	selection := addSelectionFlags(flags)
+	outDir := flags.String("out", filepath.Join("testdata", "whook"), "Directory the fixtures are written to")
+	helper := flags.String("helper", "whook_fixtures_test.go", "Go file the request helper is written to, empty to skip it")
+	pkg := flags.String("package", "", "Package name for the helper (defaults to the helper's directory name)")
+	resign := flags.Bool("resign", false, "Re-sign fixtures with each service's signing configuration")
+	keepSecrets := flags.Bool("keep-secrets", false, "Write credentials such as Authorization and the captured signatures instead of redacting them")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+
+	store, err := selection.openStore(cfg)
+	if err != nil {
+		return err
+	}
+
+	matches, err := selection.run(cfg, store)
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("no events matched the selection")
+	}
+
+	opts := fixtures.Options{
+		OutDir:           *outDir,
+		HelperPath:       *helper,
+		Package:          *pkg,
+		Signers:          map[string]signing.Signer{},
+		SignatureHeaders: cfg.SignatureHeaders(),
+		KeepSecrets:      *keepSecrets,
+	}
+
+	if opts.HelperPath != "" && opts.Package == "" {
+		opts.Package, err = packageName(opts.HelperPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	if *resign {
+		for name, service := range cfg.Services {
+			if service.Signing.Scheme == "" {
+				continue
+			}
+			signer, err := replay.SignerFor(service)
+			if err != nil {
+				return fmt.Errorf("configuring signing for %s: %w", name, err)
+			}
+			opts.Signers[name] = signer
+		}
+	}
+
+	names, err := fixtures.Export(matches, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		fmt.Println(filepath.Join(*outDir, filepath.FromSlash(name)))
+	}
+	if opts.HelperPath != "" {
+		fmt.Printf("Wrote %d fixtures and %s\n", len(names), opts.HelperPath)
+	}
+
+	return nil
+}
+
+// packageName guesses the package of the directory a helper is written to
+func packageName(helperPath string) (string, error) {
+	dir, err := filepath.Abs(filepath.Dir(helperPath))
+	if err != nil {
+		return "", fmt.Errorf("resolving helper directory: %w", err)
+	}
+
+	name := strings.Map(func(r rune) rune {
+		if r == '-' || r == '.' {
+			return '_'
+		}
+		return r
+	}, filepath.Base(dir))
+
+	return strings.ToLower(name), nil
+}
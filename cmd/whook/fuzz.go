@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/lukeberry99/whook/internal/config"
+	"github.com/lukeberry99/whook/internal/fuzz"
+	"github.com/lukeberry99/whook/internal/replay"
+	"github.com/lukeberry99/whook/internal/storage"
+)
+
+func runFuzz(args []string) error {
+	flags := flag.NewFlagSet("fuzz", flag.ExitOnError)
+	target := flags.String("to", "", "URL to send the mutated events to (defaults to the service's replay_url)")
+	serviceName := flags.String("service", "", "Service whose signing and transform configuration should be used")
+	timeout := flags.Duration("timeout", 10*time.Second, "How long to wait for each response")
+	limit := flags.Int("limit", 500, "Maximum number of mutations to send, 0 for no limit")
+	verbose := flags.Bool("v", false, "Print the result of every mutation, not just failures")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: whook fuzz [flags] <event file>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected exactly one event file")
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+
+	service := cfg.Services[*serviceName]
+	if *target == "" {
+		*target = service.ReplayURL
+	}
+	if *target == "" {
+		return fmt.Errorf("no target given, use --to or set replay_url for the service")
+	}
+
+	data, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("reading event file: %w", err)
+	}
+
+	body, err := storage.ExtractEventBody(data)
+	if err != nil {
+		return err
+	}
+
+	mutations, err := fuzz.Generate(body, *limit)
+	if err != nil {
+		return err
+	}
+
+	req, err := replay.ForService(service)
+	if err != nil {
+		return err
+	}
+	req.Target = *target
+
+	client := &http.Client{Timeout: *timeout}
+	fmt.Printf("Sending %d mutations to %s\n", len(mutations), *target)
+
+	var serverErrors, timeouts, failures int
+	for _, mutation := range mutations {
+		req.Body, err = mutation.Body()
+		if err != nil {
+			fmt.Printf("SKIPPED %v\n", err)
+			continue
+		}
+
+		result, err := replay.Send(context.Background(), client, req)
+		var netErr net.Error
+		switch {
+		case errors.As(err, &netErr) && netErr.Timeout():
+			timeouts++
+			fmt.Printf("TIMEOUT %s\n", mutation.Name)
+		case err != nil:
+			// A crash can show up as a reset connection rather than a 5xx,
+			// so keep going and report it with the rest
+			failures++
+			fmt.Printf("ERROR   %s: %v\n", mutation.Name, err)
+		case result.StatusCode >= 500:
+			serverErrors++
+			fmt.Printf("%d     %s\n", result.StatusCode, mutation.Name)
+		case *verbose:
+			fmt.Printf("%d     %s\n", result.StatusCode, mutation.Name)
+		}
+	}
+
+	fmt.Printf("\n%d mutations sent, %d server errors, %d timeouts, %d connection errors\n", len(mutations), serverErrors, timeouts, failures)
+
+	// Exit non-zero so a CI run fails when the target mishandled a mutation
+	if failed := serverErrors + timeouts + failures; failed > 0 {
+		return fmt.Errorf("%d of %d mutations failed", failed, len(mutations))
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lukeberry99/whook/internal/config"
+	"github.com/lukeberry99/whook/internal/generate"
+	"github.com/lukeberry99/whook/internal/replay"
+	"github.com/lukeberry99/whook/internal/storage"
+)
+
+// varFlags collects repeated --var key=value flags
+type varFlags map[string]string
+
+func (v varFlags) String() string {
+	pairs := make([]string, 0, len(v))
+	for key, value := range v {
+		pairs = append(pairs, key+"="+value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (v varFlags) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	v[key] = val
+	return nil
+}
+
+func runGenerate(args []string) error {
+	vars := varFlags{}
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	serviceName := flags.String("service", "chargebee", "Service whose templates should be used")
+	list := flags.Bool("list", false, "List the available templates and exit")
+	count := flags.Int("count", 1, "Number of events to generate")
+	store := flags.Bool("store", false, "Store the generated events instead of printing them")
+	target := flags.String("to", "", "Deliver the generated events to this URL")
+	flags.Var(vars, "var", "Template variable as key=value, can be repeated")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: whook generate [flags] <template>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+
+	service := cfg.Services[*serviceName]
+	dir := generate.Dir(*serviceName, service)
+
+	if *list {
+		templates, err := generate.List(*serviceName, dir)
+		if err != nil {
+			return err
+		}
+		for _, t := range templates {
+			fmt.Printf("%-30s %s\n", t.Name, t.Source)
+		}
+		return nil
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected exactly one template name")
+	}
+
+	tmpl, err := generate.Find(*serviceName, dir, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var fileStore *storage.FileStorage
+	if *store {
+		fileStore, err = storage.NewFileStorage(cfg.Storage.Path)
+		if err != nil {
+			return fmt.Errorf("opening storage: %w", err)
+		}
+	}
+
+	var req replay.Request
+	if *target != "" {
+		req, err = replay.ForService(service)
+		if err != nil {
+			return err
+		}
+		req.Target = *target
+	}
+
+	for i := 0; i < *count; i++ {
+		body, err := tmpl.Render(vars)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case *store:
+			filename, err := fileStore.StoreBody(*serviceName, time.Now(), body)
+			if err != nil {
+				return err
+			}
+			fmt.Println(filename)
+		case *target != "":
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			req.Body = body
+			result, err := replay.Send(ctx, nil, req)
+			cancel()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%d in %s\n", result.StatusCode, result.Duration.Round(time.Millisecond))
+		default:
+			fmt.Println(string(body))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lukeberry99/whook/internal/config"
+	"github.com/lukeberry99/whook/internal/importer"
+	"github.com/lukeberry99/whook/internal/storage"
+)
+
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	format := flags.String("format", "", "Import format: "+strings.Join(importer.Formats(), ", ")+" (detected when empty)")
+	service := flags.String("service", "", "Service to store events under when the source doesn't record one")
+	tag := flags.String("tag", "", "Extra tag for the imported events, e.g. an incident name")
+	session := flags.String("session", "", "Session to import into, created when it doesn't exist")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: whook import [flags] <file>...")
+		fmt.Fprintln(flags.Output(), "Pass - to read from stdin.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("expected at least one file to import")
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+
+	store, err := storage.NewFileStorage(cfg.Storage.Path)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	if err := store.SetSession(*session); err != nil {
+		return err
+	}
+
+	for _, path := range flags.Args() {
+		var data []byte
+		if path == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(path)
+		}
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", path, err)
+		}
+
+		opts := importer.Options{
+			Format:  *format,
+			Source:  filepath.Base(path),
+			Service: *service,
+		}
+		if *tag != "" {
+			opts.Tags = []string{*tag}
+		}
+		if opts.Format == "" {
+			opts.Format, err = importer.Detect(data)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+		}
+		// The events list API only ever returns Chargebee events
+		if opts.Format == importer.FormatChargebee && opts.Service == "" {
+			opts.Service = "chargebee"
+		}
+
+		events, err := importer.Parse(opts.Format, data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		result, err := importer.Store(store, events, opts)
+		for _, filename := range result.Stored {
+			fmt.Println(filename)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		for _, skipped := range result.Skipped {
+			fmt.Fprintf(os.Stderr, "Skipped %s\n", skipped)
+		}
+
+		fmt.Fprintf(os.Stderr, "Imported %d events from %s (%s)\n", len(result.Stored), path, opts.Format)
+	}
+
+	return nil
+}
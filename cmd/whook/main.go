@@ -6,19 +6,48 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/lukeberry99/whook/internal/config"
+	"github.com/lukeberry99/whook/internal/hooks"
+	"github.com/lukeberry99/whook/internal/scheduler"
 	"github.com/lukeberry99/whook/internal/server"
 	"github.com/lukeberry99/whook/internal/storage"
 	"github.com/lukeberry99/whook/internal/tunnel"
 	"github.com/lukeberry99/whook/internal/ui"
 )
 
+var commands = map[string]func(args []string) error{
+	"browse":   runBrowse,
+	"bundle":   runBundle,
+	"emulate":  runEmulate,
+	"export":   runExport,
+	"fixtures": runFixtures,
+	"fuzz":     runFuzz,
+	"generate": runGenerate,
+	"import":   runImport,
+	"replay":   runReplay,
+	"session":  runSession,
+	"tag":      runTag,
+}
+
 func main() {
+	// Dispatch subcommands before parsing the top level flags
+	if len(os.Args) > 1 {
+		if run, ok := commands[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	// Add version flag
 	showVersion := flag.Bool("version", false, "Show version information")
+	session := flag.String("session", "", "Store webhooks in a named session instead of the default one")
 	flag.Parse()
 
 	// Handle version flag
@@ -44,11 +73,32 @@ func main() {
 		logChan <- fmt.Sprintf("Failed to create storage: %v", err)
 	}
 
+	if err := store.SetSession(*session); err != nil {
+		logChan <- fmt.Sprintf("Failed to open session: %v", err)
+	} else if *session != "" {
+		logChan <- fmt.Sprintf("Using session %s", *session)
+	}
+
+	pruned, err := store.PruneSessions(cfg.Storage.Retention)
+	if err != nil {
+		logChan <- fmt.Sprintf("Failed to apply retention: %v", err)
+	}
+	for name, count := range pruned {
+		logChan <- fmt.Sprintf("Removed %d events past the retention of session %s", count, name)
+	}
+
+	sched, err := scheduler.New(cfg, store, logChan)
+	if err != nil {
+		logChan <- fmt.Sprintf("Failed to create scheduler: %v", err)
+	}
+
+	tunnelStatus := &tunnel.Status{}
+
 	logChan <- "Initialising UI..."
 	uiDone := make(chan struct{})
 	uiErr := make(chan error, 1)
 	go func() {
-		if err := ui.StartUI(cfg, logChan, store); err != nil {
+		if err := ui.StartUI(cfg, logChan, store, sched, tunnelStatus); err != nil {
 			logChan <- fmt.Sprintf("UI Error: %v", err)
 			uiErr <- err
 			close(uiDone)
@@ -96,22 +146,63 @@ func main() {
 				tunnelServer = nil
 			} else {
 				logChan <- fmt.Sprintf("Tunnel URL: %s", tunnelURL)
+				tunnelStatus.Up(cfg.Tunnel.Driver, tunnelURL)
 			}
 		}
 	}
 
+	// The UI restarts the tunnel when it stops working, which replaces
+	// tunnelServer while shutdown may be stopping it
+	var tunnelMu sync.Mutex
+	if tunnelServer != nil {
+		tunnelStatus.SetRestart(func() (string, error) {
+			tunnelMu.Lock()
+			defer tunnelMu.Unlock()
+
+			if tunnelServer != nil {
+				if err := tunnelServer.Stop(); err != nil {
+					return "", fmt.Errorf("stopping tunnel: %w", err)
+				}
+				tunnelServer = nil
+				tunnelStatus.Down()
+			}
+
+			restarted, err := tunnel.New(tunnel.Config{
+				Provider:        tunnel.Provider(cfg.Tunnel.Driver),
+				CloudflareToken: cfg.Tunnel.CloudflareToken,
+			})
+			if err != nil {
+				return "", fmt.Errorf("creating tunnel: %w", err)
+			}
+			url, err := restarted.Start()
+			if err != nil {
+				return "", fmt.Errorf("starting tunnel: %w", err)
+			}
+
+			tunnelServer = restarted
+			tunnelStatus.Up(cfg.Tunnel.Driver, url)
+			return url, nil
+		})
+	}
+
 	if cfg.Tunnel.Driver == "local" {
 		url := fmt.Sprintf("http://localhost:%d", cfg.Server.Port)
 		logChan <- "Running in local mode - no tunnel started"
 		logChan <- fmt.Sprintf("Tunnel URL: %s", url)
+		tunnelStatus.Up("local", url)
 	}
 
-	srv := server.NewWebhookServer(cfg, store, logChan)
+	runner := hooks.NewRunner(cfg, logChan)
+	srv := server.NewWebhookServer(cfg, store, runner, logChan)
 	serverErrors := make(chan error, 1)
 	go func() {
 		serverErrors <- srv.ListenAndServe()
 	}()
 
+	if sched != nil {
+		sched.Start()
+	}
+
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
 
@@ -134,11 +225,21 @@ func main() {
 		}
 	}
 
+	// Hooks and schedules log as they finish, so they have to be done before
+	// the log channel is closed
+	runner.Stop()
+	if sched != nil {
+		sched.Stop()
+	}
+
+	tunnelMu.Lock()
 	if tunnelServer != nil {
 		if err := tunnelServer.Stop(); err != nil {
 			logChan <- fmt.Sprintf("Error stopping tunnel: %v", err)
 		}
+		tunnelStatus.Down()
 	}
+	tunnelMu.Unlock()
 
 	close(logChan)
 }
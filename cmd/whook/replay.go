@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lukeberry99/whook/internal/config"
+	"github.com/lukeberry99/whook/internal/replay"
+	"github.com/lukeberry99/whook/internal/storage"
+)
+
+func runReplay(args []string) error {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	target := flags.String("to", "", "URL to send the event to (defaults to the service's replay_url)")
+	serviceName := flags.String("service", "", "Service whose signing configuration should be used (defaults to the event's service)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: whook replay [flags] <event file>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected exactly one event file")
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+
+	if *serviceName == "" {
+		*serviceName = eventServiceName(cfg, flags.Arg(0))
+	}
+	service := cfg.Services[*serviceName]
+	if *target == "" {
+		*target = service.ReplayURL
+	}
+	if *target == "" {
+		return fmt.Errorf("no target given, use --to or set replay_url for the service")
+	}
+
+	data, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("reading event file: %w", err)
+	}
+
+	body, err := storage.ExtractEventBody(data)
+	if err != nil {
+		return err
+	}
+
+	req, err := replay.ForService(service)
+	if err != nil {
+		return err
+	}
+	req.Target = *target
+	req.Body = body
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := replay.Send(ctx, nil, req)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d in %s\n", result.StatusCode, result.Duration.Round(time.Millisecond))
+	if len(result.Body) > 0 {
+		fmt.Println(string(result.Body))
+	}
+
+	return nil
+}
+
+// eventServiceName returns the configured service an event file was stored
+// under, going by its directory or its HHMMSS_<service>_ filename
+func eventServiceName(cfg *config.Config, path string) string {
+	dir := filepath.Base(filepath.Dir(path))
+	if _, ok := cfg.Services[dir]; ok {
+		return dir
+	}
+
+	name := filepath.Base(path)
+	if len(name) < 7 || name[6] != '_' {
+		return ""
+	}
+	// Service names can contain underscores, so the longest match wins
+	var match string
+	for service := range cfg.Services {
+		if len(service) > len(match) && strings.HasPrefix(name[7:], service+"_") {
+			match = service
+		}
+	}
+	return match
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/lukeberry99/whook/internal/config"
+	"github.com/lukeberry99/whook/internal/query"
+	"github.com/lukeberry99/whook/internal/storage"
+)
+
+// selectionFlags are shared by the commands that operate on a selection of
+// stored events
+type selectionFlags struct {
+	expr    *string
+	service *string
+	tag     *string
+	session *string
+}
+
+func addSelectionFlags(flags *flag.FlagSet) *selectionFlags {
+	return &selectionFlags{
+		expr:    flags.String("query", "", "Query selecting events, e.g. 'service:chargebee type:payment_succeeded since:24h'"),
+		service: flags.String("service", "", "Only select events from this service"),
+		tag:     flags.String("tag", "", "Only select events with this tag"),
+		session: flags.String("session", "", "Select events from this session instead of the default one"),
+	}
+}
+
+// openStore opens the storage of the selected session
+func (s *selectionFlags) openStore(cfg *config.Config) (*storage.FileStorage, error) {
+	store, err := storage.NewFileStorage(cfg.Storage.Path)
+	if err != nil {
+		return nil, fmt.Errorf("opening storage: %w", err)
+	}
+
+	if *s.session == "" {
+		return store, nil
+	}
+	return store.InSession(*s.session)
+}
+
+func (s *selectionFlags) run(cfg *config.Config, store *storage.FileStorage) ([]query.Match, error) {
+	q, err := query.Parse(*s.expr)
+	if err != nil {
+		return nil, err
+	}
+	if *s.service != "" {
+		q.Services = append(q.Services, *s.service)
+	}
+	if *s.tag != "" {
+		q.Tags = append(q.Tags, *s.tag)
+	}
+
+	matches, err := query.Run(store, cfg, q)
+	if err != nil {
+		return nil, fmt.Errorf("selecting events: %w", err)
+	}
+
+	return matches, nil
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lukeberry99/whook/internal/bundle"
+	"github.com/lukeberry99/whook/internal/config"
+	"github.com/lukeberry99/whook/internal/query"
+	"github.com/lukeberry99/whook/internal/storage"
+)
+
+var sessionCommands = map[string]func(cfg *config.Config, store *storage.FileStorage, args []string) error{
+	"list":      runSessionList,
+	"retention": runSessionRetention,
+	"prune":     runSessionPrune,
+	"archive":   runSessionArchive,
+	"remove":    runSessionRemove,
+}
+
+func runSession(args []string) error {
+	if len(args) == 0 || sessionCommands[args[0]] == nil {
+		fmt.Fprintln(os.Stderr, "Usage: whook session list|retention|prune|archive|remove [flags]")
+		return fmt.Errorf("unknown session command")
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+
+	store, err := storage.NewFileStorage(cfg.Storage.Path)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+
+	return sessionCommands[args[0]](cfg, store, args[1:])
+}
+
+func runSessionList(cfg *config.Config, store *storage.FileStorage, args []string) error {
+	sessions, err := store.Sessions()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SESSION\tEVENTS\tCREATED\tRETENTION")
+	for _, session := range sessions {
+		view, err := store.InSession(session.Name)
+		if err != nil {
+			return err
+		}
+		items, err := view.ListAll()
+		if err != nil {
+			return err
+		}
+
+		created := "-"
+		if !session.CreatedAt.IsZero() {
+			created = session.CreatedAt.Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", session.DisplayName(), len(items), created, retentionText(session.Retention, cfg.Storage.Retention))
+	}
+
+	return w.Flush()
+}
+
+func retentionText(retention, fallback time.Duration) string {
+	switch {
+	case retention > 0:
+		return retention.String()
+	case fallback > 0:
+		return fallback.String() + " (default)"
+	default:
+		return "forever"
+	}
+}
+
+func runSessionRetention(cfg *config.Config, store *storage.FileStorage, args []string) error {
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, "Usage: whook session retention <session> <duration>")
+		fmt.Fprintln(os.Stderr, "A duration of 0 uses storage.retention from the config.")
+		return fmt.Errorf("expected a session and a duration")
+	}
+
+	retention, err := time.ParseDuration(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid retention: %w", err)
+	}
+
+	if err := store.SetSessionRetention(sessionArg(args[0]), retention); err != nil {
+		return err
+	}
+
+	fmt.Printf("Session %s keeps events for %s\n", args[0], retentionText(retention, cfg.Storage.Retention))
+	return nil
+}
+
+func runSessionPrune(cfg *config.Config, store *storage.FileStorage, args []string) error {
+	pruned, err := store.PruneSessions(cfg.Storage.Retention)
+	for name, count := range pruned {
+		fmt.Printf("Removed %d events from %s\n", count, name)
+	}
+	return err
+}
+
+// runSessionArchive bundles a session and then removes it, the bundle can be
+// browsed again with whook bundle open
+func runSessionArchive(cfg *config.Config, store *storage.FileStorage, args []string) error {
+	flags := flag.NewFlagSet("session archive", flag.ExitOnError)
+	out := flags.String("out", "", "Archive file to write (defaults to <storage>/.archive/<session>-<date>.tar.gz)")
+	keep := flags.Bool("keep", false, "Keep the session after archiving it")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: whook session archive [flags] <session>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 || flags.Arg(0) == "default" {
+		flags.Usage()
+		return fmt.Errorf("expected a named session")
+	}
+	name := flags.Arg(0)
+
+	view, err := store.InSession(name)
+	if err != nil {
+		return err
+	}
+	matches, err := query.Run(view, cfg, query.Query{})
+	if err != nil {
+		return err
+	}
+	items, err := view.ListAll()
+	if err != nil {
+		return err
+	}
+
+	path := *out
+	if path == "" {
+		path = filepath.Join(store.Root(), ".archive", fmt.Sprintf("%s-%s.tar.gz", name, time.Now().Format("20060102-150405")))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("creating archive directory: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", path, err)
+	}
+	defer f.Close()
+
+	// The archive replaces the session, so it keeps the headers exactly as
+	// they were captured along with the drafts
+	opts := bundle.CreateOptions{
+		Notes:       "Archive of session " + name,
+		KeepSecrets: true,
+		Drafts:      true,
+	}
+	if _, err := bundle.Create(f, view, matches, cfg, opts); err != nil {
+		return err
+	}
+	// Make sure the archive is complete before the session is removed
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	fmt.Printf("Archived %d events from %s to %s\n", len(matches), name, path)
+
+	if *keep {
+		return nil
+	}
+	// Events that couldn't be read weren't archived, removing the session
+	// would lose them
+	if len(matches) != len(items) {
+		return fmt.Errorf("%d events in %s couldn't be read, keeping the session", len(items)-len(matches), name)
+	}
+	if err := store.RemoveSession(name); err != nil {
+		return err
+	}
+	fmt.Printf("Removed session %s\n", name)
+
+	return nil
+}
+
+func runSessionRemove(cfg *config.Config, store *storage.FileStorage, args []string) error {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: whook session remove <session>")
+		return fmt.Errorf("expected a session")
+	}
+
+	if err := store.RemoveSession(sessionArg(args[0])); err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed session %s\n", args[0])
+	return nil
+}
+
+// sessionArg maps the "default" name shown in listings to the default session
+func sessionArg(name string) string {
+	if name == "default" {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/lukeberry99/whook/internal/config"
+	"github.com/lukeberry99/whook/internal/storage"
+)
+
+func runTag(args []string) error {
+	flags := flag.NewFlagSet("tag", flag.ExitOnError)
+	clearTags := flags.Bool("clear", false, "Remove every tag from the event")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: whook tag [flags] <event file> [tag...]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("expected an event file")
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+
+	store, err := storage.NewFileStorage(cfg.Storage.Path)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	store = store.ForEvent(flags.Arg(0))
+
+	filename := filepath.Base(flags.Arg(0))
+	tags, err := store.Tags(filename)
+	if err != nil {
+		return err
+	}
+
+	if *clearTags {
+		tags = nil
+	}
+	tags = append(tags, flags.Args()[1:]...)
+
+	if *clearTags || flags.NArg() > 1 {
+		if err := store.SetTags(filename, tags); err != nil {
+			return err
+		}
+		tags, err = store.Tags(filename)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Println(strings.Join(tags, " "))
+	return nil
+}
@@ -0,0 +1,541 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lukeberry99/whook/internal/config"
+	"github.com/lukeberry99/whook/internal/query"
+	"github.com/lukeberry99/whook/internal/signing"
+	"github.com/lukeberry99/whook/internal/storage"
+	"github.com/lukeberry99/whook/internal/version"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	manifestFile = "manifest.json"
+	notesFile    = "NOTES.md"
+	configFile   = "config.yaml"
+	// sourceFile records the checksum of the bundle a directory was opened
+	// from, it's written next to the extracted files rather than bundled
+	sourceFile = ".bundle.sha256"
+	// StoreDir is the directory inside a bundle, and an opened bundle, that
+	// holds a regular whook storage directory
+	StoreDir = "store"
+
+	formatVersion = 1
+)
+
+type Manifest struct {
+	Version      int             `json:"version"`
+	CreatedAt    time.Time       `json:"created_at"`
+	WhookVersion string          `json:"whook_version"`
+	Query        string          `json:"query,omitempty"`
+	Events       []ManifestEvent `json:"events"`
+}
+
+type ManifestEvent struct {
+	Service    string    `json:"service,omitempty"`
+	Filename   string    `json:"filename"`
+	Path       string    `json:"path"`
+	EventType  string    `json:"event_type,omitempty"`
+	Tags       []string  `json:"tags,omitempty"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+type CreateOptions struct {
+	Notes string
+	// Query is recorded in the manifest to say how the events were selected
+	Query string
+	// KeepSignatures leaves signature headers in the bundled events, so
+	// whoever opens it can verify them with their own copy of the secret.
+	// Credentials such as Authorization are redacted unless KeepSecrets is
+	// set.
+	KeepSignatures bool
+	// KeepSecrets leaves every header as it was captured, for archives that
+	// replace the events rather than share them
+	KeepSecrets bool
+	// Drafts adds the store's drafts alongside the events
+	Drafts bool
+}
+
+// Create writes a tar.gz bundle of the matched event files, their tags and
+// attempt logs, a manifest, the notes and the configuration of the services
+// involved with any secrets redacted. Credential and signature headers are
+// redacted from the event files unless opts.KeepSecrets is set.
+func Create(w io.Writer, store *storage.FileStorage, matches []query.Match, cfg *config.Config, opts CreateOptions) (*Manifest, error) {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+
+	manifest := &Manifest{
+		Version:      formatVersion,
+		CreatedAt:    now,
+		WhookVersion: version.Version,
+		Query:        opts.Query,
+	}
+
+	tags := map[string][]string{}
+	services := map[string]bool{}
+
+	for _, match := range matches {
+		rel := match.Item.Filename
+		if match.Item.ServiceName != "" {
+			rel = path.Join(match.Item.ServiceName, rel)
+		}
+		rel = path.Join(StoreDir, rel)
+
+		data, err := os.ReadFile(match.Item.Path)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", match.Item.Path, err)
+		}
+		if !opts.KeepSecrets {
+			data, err = redactEvent(data, opts.KeepSignatures, cfg.Services[match.Item.ServiceName].Signing.Header)
+			if err != nil {
+				return nil, fmt.Errorf("redacting %s: %w", match.Item.Filename, err)
+			}
+		}
+		if err := addData(tw, rel, data, now); err != nil {
+			return nil, err
+		}
+
+		attempts := store.AttemptsPath(match.Item.Filename)
+		if _, err := os.Stat(attempts); err == nil {
+			if err := addFile(tw, path.Join(StoreDir, filepath.ToSlash(store.RelPath(attempts))), attempts, now); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(match.Tags) > 0 {
+			tags[match.Item.Filename] = match.Tags
+		}
+		services[match.Item.ServiceName] = true
+
+		manifest.Events = append(manifest.Events, ManifestEvent{
+			Service:    match.Item.ServiceName,
+			Filename:   match.Item.Filename,
+			Path:       rel,
+			EventType:  match.EventType,
+			Tags:       match.Tags,
+			ReceivedAt: match.Event.ReceivedAt,
+		})
+	}
+
+	if len(tags) > 0 {
+		data, err := json.MarshalIndent(tags, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("encoding tags: %w", err)
+		}
+		if err := addData(tw, path.Join(StoreDir, ".tags.json"), data, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Drafts {
+		drafts, err := store.ListDrafts()
+		if err != nil {
+			return nil, err
+		}
+		for _, draft := range drafts {
+			if err := addFile(tw, path.Join(StoreDir, filepath.ToSlash(store.RelPath(draft.Path))), draft.Path, now); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	snippet, err := configSnippet(cfg, services)
+	if err != nil {
+		return nil, err
+	}
+	if err := addData(tw, configFile, snippet, now); err != nil {
+		return nil, err
+	}
+
+	if opts.Notes != "" {
+		if err := addData(tw, notesFile, []byte(opts.Notes), now); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding manifest: %w", err)
+	}
+	if err := addData(tw, manifestFile, data, now); err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("writing bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("writing bundle: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// redactEvent masks the credential and signature headers of an event file,
+// everything else is kept as it was stored
+func redactEvent(data []byte, keepSignatures bool, signatureHeader string) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("decoding event file: %w", err)
+	}
+	if _, ok := fields["headers"]; !ok {
+		return data, nil
+	}
+
+	var headers http.Header
+	if err := json.Unmarshal(fields["headers"], &headers); err != nil {
+		return nil, fmt.Errorf("decoding headers: %w", err)
+	}
+	encoded, err := json.Marshal(signing.RedactHeaders(headers, keepSignatures, signatureHeader))
+	if err != nil {
+		return nil, fmt.Errorf("encoding headers: %w", err)
+	}
+	fields["headers"] = encoded
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(fields); err != nil {
+		return nil, fmt.Errorf("encoding event file: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func addFile(tw *tar.Writer, name, src string, modTime time.Time) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", src, err)
+	}
+	return addData(tw, name, data, modTime)
+}
+
+func addData(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	return nil
+}
+
+// configSnippet returns the services section for the bundled services, so
+// event types are detected the same way when the bundle is opened
+func configSnippet(cfg *config.Config, services map[string]bool) ([]byte, error) {
+	snippet := struct {
+		Services map[string]config.ServiceConfig `yaml:"services"`
+	}{Services: map[string]config.ServiceConfig{}}
+
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		service, ok := cfg.Services[name]
+		if !ok {
+			continue
+		}
+		if service.Signing.Secret != "" {
+			service.Signing.Secret = signing.Redacted
+		}
+		if service.Signing.Password != "" {
+			service.Signing.Password = signing.Redacted
+		}
+		// Hooks run local commands, they don't belong in a shared bundle
+		service.Hooks = nil
+		service.TemplatesDir = ""
+		snippet.Services[name] = service
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# Configuration of the bundled services, secrets are redacted\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(snippet); err != nil {
+		return nil, fmt.Errorf("encoding config snippet: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Opened is a bundle extracted by Open
+type Opened struct {
+	Dir      string
+	Manifest Manifest
+	Notes    string
+	// Services is the bundled configuration snippet
+	Services map[string]config.ServiceConfig
+}
+
+// MergeServices applies the bundled event type detection and columns to
+// cfg. Signing, replay URLs, hooks and transforms always stay local, the
+// bundled secrets are redacted and its URLs point at the author's machine.
+func (o *Opened) MergeServices(cfg *config.Config) {
+	if cfg.Services == nil {
+		cfg.Services = map[string]config.ServiceConfig{}
+	}
+
+	for name, bundled := range o.Services {
+		service := cfg.Services[name]
+		// The bundled event types describe the events, so they win
+		if bundled.EventTypeSource != "" {
+			service.EventTypeSource = bundled.EventTypeSource
+			service.EventTypeLocation = bundled.EventTypeLocation
+		}
+		for set, columns := range bundled.Columns {
+			if _, ok := service.Columns[set]; ok {
+				continue
+			}
+			if service.Columns == nil {
+				service.Columns = map[string][]string{}
+			}
+			service.Columns[set] = columns
+		}
+		cfg.Services[name] = service
+	}
+}
+
+// StorePath returns the storage directory of the opened bundle
+func (o *Opened) StorePath() string {
+	return filepath.Join(o.Dir, StoreDir)
+}
+
+// DefaultDir returns the directory a bundle is opened into when no directory
+// is given, kept apart from the regular storage directory
+func DefaultDir(bundlePath string) string {
+	name := filepath.Base(bundlePath)
+	for _, ext := range []string{".gz", ".tgz", ".tar"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return filepath.Join(".", "whook-bundles", name)
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+
+	return filepath.Join(dataHome, "whook", "bundles", name)
+}
+
+// Open extracts a bundle into dir and makes it read-only. The same bundle
+// opened into dir before is loaded as it is, a different one, such as
+// another bundle with the same file name, replaces it.
+func Open(bundlePath, dir string) (*Opened, error) {
+	sum, err := checksum(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if IsExtracted(dir) {
+		recorded, err := os.ReadFile(filepath.Join(dir, sourceFile))
+		if err == nil && strings.TrimSpace(string(recorded)) == sum {
+			return Load(dir)
+		}
+		if err := removeExtracted(dir); err != nil {
+			return nil, err
+		}
+	}
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("%s isn't empty, choose another directory to open the bundle into", dir)
+	}
+
+	f, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("opening bundle: %w", err)
+	}
+	defer f.Close()
+
+	if err := extract(f, dir); err != nil {
+		// Don't leave a half extracted bundle to be loaded next time
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+
+	opened, err := Load(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, sourceFile), []byte(sum+"\n"), 0644); err != nil {
+		return nil, fmt.Errorf("recording bundle checksum: %w", err)
+	}
+	if err := makeReadOnly(dir); err != nil {
+		return nil, err
+	}
+
+	return opened, nil
+}
+
+// checksum returns the SHA-256 of the bundle file as hex
+func checksum(bundlePath string) (string, error) {
+	f, err := os.Open(bundlePath)
+	if err != nil {
+		return "", fmt.Errorf("opening bundle: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("reading bundle: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// removeExtracted deletes a bundle opened into dir, giving its directories
+// back the write permission makeReadOnly removed so their files can go
+func removeExtracted(dir string) error {
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return os.Chmod(p, 0755)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("removing the bundle opened in %s: %w", dir, err)
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("removing the bundle opened in %s: %w", dir, err)
+	}
+	return nil
+}
+
+func extract(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("reading bundle: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading bundle: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		// Refuse anything that would land outside the bundle directory
+		name := path.Clean(header.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("bundle contains an invalid path %q", header.Name)
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("creating %s: %w", filepath.Dir(target), err)
+		}
+
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return fmt.Errorf("creating %s: %w", target, err)
+		}
+		_, err = io.Copy(out, tr)
+		out.Close()
+		if err != nil {
+			return fmt.Errorf("extracting %s: %w", name, err)
+		}
+	}
+}
+
+// IsExtracted reports whether dir holds an extracted bundle
+func IsExtracted(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, manifestFile))
+	return err == nil
+}
+
+// Load reads a bundle that has already been extracted into dir
+func Load(dir string) (*Opened, error) {
+	opened := &Opened{Dir: dir}
+
+	data, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, &opened.Manifest); err != nil {
+		return nil, fmt.Errorf("decoding manifest: %w", err)
+	}
+	if opened.Manifest.Version > formatVersion {
+		return nil, fmt.Errorf("bundle version %d is newer than this whook supports, please upgrade", opened.Manifest.Version)
+	}
+
+	if notes, err := os.ReadFile(filepath.Join(dir, notesFile)); err == nil {
+		opened.Notes = string(notes)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, configFile)); err == nil {
+		var snippet struct {
+			Services map[string]config.ServiceConfig `yaml:"services"`
+		}
+		if err := yaml.Unmarshal(data, &snippet); err != nil {
+			return nil, fmt.Errorf("decoding bundled config: %w", err)
+		}
+		opened.Services = snippet.Services
+	}
+
+	return opened, nil
+}
+
+// makeReadOnly removes write permission from everything under dir, so the
+// opened bundle stays exactly as it was received
+func makeReadOnly(dir string) error {
+	var dirs []string
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			dirs = append(dirs, p)
+			return nil
+		}
+		return os.Chmod(p, 0444)
+	})
+	if err != nil {
+		return fmt.Errorf("making bundle read-only: %w", err)
+	}
+
+	// Directories last, deepest first, so the walk can still list them
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := os.Chmod(dirs[i], 0555); err != nil {
+			return fmt.Errorf("making bundle read-only: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -14,17 +15,153 @@ type Config struct {
 	} `yaml:"server"`
 	Storage struct {
 		Path string `yaml:"path"`
+		// Retention is how long sessions keep events unless they set their
+		// own, zero keeps them forever
+		Retention time.Duration `yaml:"retention,omitempty"`
 	} `yaml:"storage"`
 	Tunnel struct {
 		Driver          string `yaml:"driver"`
 		CloudflareToken string `yaml:"cloudflare_token,omitempty"`
 	} `yaml:"tunnel"`
+	Hooks struct {
+		MaxConcurrency int `yaml:"max_concurrency"`
+	} `yaml:"hooks"`
 	Services map[string]ServiceConfig `yaml:"services"`
+	// Targets are named URLs that drafts and replays can be sent to
+	Targets   map[string]string `yaml:"targets,omitempty"`
+	Schedules []ScheduleConfig  `yaml:"schedules,omitempty"`
+	UI        UIConfig          `yaml:"ui,omitempty"`
+}
+
+// UIConfig holds terminal UI preferences, which the UI saves as they change
+type UIConfig struct {
+	// Columns of the request table, built in columns, $ JSON paths or jq
+	// expressions starting with .
+	Columns []string `yaml:"columns,omitempty"`
+	// Filter is a $ JSON path or jq expression, only events it produces a
+	// value other than false or null for are listed
+	Filter string `yaml:"filter,omitempty"`
+	// DiffIgnore holds the key names and $ JSON paths left out when
+	// comparing events, such as IDs and timestamps
+	DiffIgnore []string `yaml:"diff_ignore,omitempty"`
+	// Keys binds actions by name to keys, such as replay: R or quit: ctrl-q
+	Keys map[string]string `yaml:"keys,omitempty"`
+	// ConfirmQuit asks before quitting, it's on unless set to false
+	ConfirmQuit *bool `yaml:"confirm_quit,omitempty"`
+	// Theme is dark or light, Colors overrides its background, text, border,
+	// title, accent and selection colours
+	Theme  string            `yaml:"theme,omitempty"`
+	Colors map[string]string `yaml:"colors,omitempty"`
+	// ListWidth is the percentage of the width given to the request table,
+	// the details get the rest
+	ListWidth int `yaml:"list_width,omitempty"`
+}
+
+// TableWidth returns ListWidth, defaulting to 20 and kept between 10 and 90
+func (c UIConfig) TableWidth() int {
+	switch {
+	case c.ListWidth == 0:
+		return 20
+	case c.ListWidth < 10:
+		return 10
+	case c.ListWidth > 90:
+		return 90
+	}
+	return c.ListWidth
+}
+
+// ShouldConfirmQuit reports whether quitting has to be confirmed
+func (c UIConfig) ShouldConfirmQuit() bool {
+	return c.ConfirmQuit == nil || *c.ConfirmQuit
+}
+
+// ScheduleConfig fires an event generated from Template, or read from the
+// captured Event file, either Every interval or on a Cron expression. Events
+// go to Target, a named target or URL, or are stored in whook when it's empty.
+type ScheduleConfig struct {
+	Name     string            `yaml:"name"`
+	Service  string            `yaml:"service"`
+	Template string            `yaml:"template,omitempty"`
+	Event    string            `yaml:"event,omitempty"`
+	Vars     map[string]string `yaml:"vars,omitempty"`
+	Every    time.Duration     `yaml:"every,omitempty"`
+	Cron     string            `yaml:"cron,omitempty"`
+	Target   string            `yaml:"target,omitempty"`
 }
 
 type ServiceConfig struct {
-	EventTypeSource   string `yaml:"event_type_source"`
-	EventTypeLocation string `yaml:"event_type_location"`
+	EventTypeSource   string        `yaml:"event_type_source"`
+	EventTypeLocation string        `yaml:"event_type_location"`
+	ReplayURL         string        `yaml:"replay_url,omitempty"`
+	Signing           SigningConfig `yaml:"signing,omitempty"`
+	Hooks             []HookConfig  `yaml:"hooks,omitempty"`
+	// Transforms are applied in order to events before they're replayed
+	Transforms []TransformConfig `yaml:"transforms,omitempty"`
+	// TemplatesDir holds synthetic event templates, it defaults to
+	// ~/.config/whook/templates/<service>
+	TemplatesDir string `yaml:"templates_dir,omitempty"`
+	// Columns are named column sets for tabular exports, each a list of
+	// fields or $ JSON paths
+	Columns map[string][]string `yaml:"columns,omitempty"`
+}
+
+// TransformConfig is a single transform step, exactly one field should be set
+type TransformConfig struct {
+	JQ       string                 `yaml:"jq,omitempty"`
+	Template string                 `yaml:"template,omitempty"`
+	Headers  *HeaderTransformConfig `yaml:"headers,omitempty"`
+	URL      *URLRewriteConfig      `yaml:"url,omitempty"`
+}
+
+type HeaderTransformConfig struct {
+	Add    map[string]string `yaml:"add,omitempty"`
+	Remove []string          `yaml:"remove,omitempty"`
+	Rename map[string]string `yaml:"rename,omitempty"`
+}
+
+// URLRewriteConfig replaces matches of a regular expression in the target
+// URL, Replace can reference capture groups with $1
+type URLRewriteConfig struct {
+	Match   string `yaml:"match"`
+	Replace string `yaml:"replace"`
+}
+
+// HookConfig is a command that's run for every matching webhook, with the
+// payload on stdin. An empty EventTypes list matches every event.
+type HookConfig struct {
+	Command    string        `yaml:"command"`
+	EventTypes []string      `yaml:"event_types,omitempty"`
+	Timeout    time.Duration `yaml:"timeout,omitempty"`
+}
+
+type SigningConfig struct {
+	Scheme   string `yaml:"scheme"`
+	Secret   string `yaml:"secret,omitempty"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	Header   string `yaml:"header,omitempty"`
+	Prefix   string `yaml:"prefix,omitempty"`
+}
+
+// ResolveTarget returns the URL of a named target, anything else is assumed
+// to already be a URL
+func (c *Config) ResolveTarget(target string) string {
+	if url, ok := c.Targets[target]; ok {
+		return url
+	}
+	return target
+}
+
+// SignatureHeaders returns the custom signature header of each service that
+// sets one, for redacting them along with the well known ones
+func (c *Config) SignatureHeaders() map[string]string {
+	headers := map[string]string{}
+	for name, service := range c.Services {
+		if service.Signing.Header != "" {
+			headers[name] = service.Signing.Header
+		}
+	}
+	return headers
 }
 
 func getConfigLocations(configPath string) []string {
@@ -0,0 +1,165 @@
+package emulator
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/lukeberry99/whook/internal/replay"
+	"github.com/lukeberry99/whook/internal/storage"
+)
+
+type Recorder interface {
+	RecordAttempt(attempt storage.Attempt) error
+}
+
+type Config struct {
+	Profile Profile
+	Target  string
+	// Request carries the signer and transforms, its target and body are
+	// filled in for each delivery
+	Request replay.Request
+	// Speed divides every backoff delay, so 60 turns an hour into a minute
+	Speed    float64
+	Recorder Recorder
+	// OnAttempt is called after every attempt, for progress output
+	OnAttempt func(attempt storage.Attempt)
+}
+
+type Delivery struct {
+	Event string
+	Body  []byte
+	// Recorder records this delivery's attempts instead of the emulator's,
+	// for events stored in different sessions
+	Recorder Recorder
+}
+
+// Emulator sends events the way a provider would, including retries and
+// disabling the endpoint after too many consecutive failures
+type Emulator struct {
+	cfg                 Config
+	client              *http.Client
+	consecutiveFailures int
+	disabled            bool
+}
+
+func New(cfg Config) *Emulator {
+	if cfg.Speed <= 0 {
+		cfg.Speed = 1
+	}
+
+	return &Emulator{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Profile.Timeout},
+	}
+}
+
+func (e *Emulator) Disabled() bool {
+	return e.disabled
+}
+
+// Deliver sends an event and retries it on the provider's schedule until it
+// succeeds, the retries run out or the endpoint is disabled
+func (e *Emulator) Deliver(ctx context.Context, delivery Delivery) (bool, error) {
+	req := e.cfg.Request
+	req.Target = e.cfg.Target
+	req.Body = delivery.Body
+	req.Headers = e.deliveryHeaders()
+
+	for attemptNumber := 1; ; attemptNumber++ {
+		if e.disabled {
+			return false, fmt.Errorf("endpoint disabled after %d consecutive failures", e.consecutiveFailures)
+		}
+
+		attempt := e.attempt(ctx, req, delivery.Event, attemptNumber)
+		recorder := e.cfg.Recorder
+		if delivery.Recorder != nil {
+			recorder = delivery.Recorder
+		}
+		if recorder != nil {
+			if err := recorder.RecordAttempt(attempt); err != nil {
+				return false, err
+			}
+		}
+		if e.cfg.OnAttempt != nil {
+			e.cfg.OnAttempt(attempt)
+		}
+
+		if attempt.Succeeded() {
+			e.consecutiveFailures = 0
+			return true, nil
+		}
+
+		e.consecutiveFailures++
+		if e.cfg.Profile.DisableAfter > 0 && e.consecutiveFailures >= e.cfg.Profile.DisableAfter {
+			// A disabled endpoint gets no more retries, so don't wait out
+			// the backoff before saying so
+			e.disabled = true
+			return false, fmt.Errorf("endpoint disabled after %d consecutive failures", e.consecutiveFailures)
+		}
+
+		if attemptNumber > len(e.cfg.Profile.Backoff) {
+			return false, nil
+		}
+
+		delay := time.Duration(float64(e.cfg.Profile.Backoff[attemptNumber-1]) / e.cfg.Speed)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+}
+
+func (e *Emulator) attempt(ctx context.Context, req replay.Request, event string, number int) storage.Attempt {
+	attempt := storage.Attempt{
+		Event:  event,
+		Source: "emulator:" + e.cfg.Profile.Name,
+		Target: req.Target,
+		Number: number,
+		SentAt: time.Now(),
+	}
+
+	result, err := replay.Send(ctx, e.client, req)
+	if err != nil {
+		attempt.Error = err.Error()
+		attempt.Duration = time.Since(attempt.SentAt)
+		return attempt
+	}
+
+	attempt.StatusCode = result.StatusCode
+	attempt.Duration = result.Duration
+	attempt.ResponseBody = string(result.Body)
+
+	return attempt
+}
+
+// deliveryHeaders are fixed for every attempt of one delivery, which is how
+// providers let consumers deduplicate retries
+func (e *Emulator) deliveryHeaders() http.Header {
+	headers := http.Header{}
+	for key, value := range e.cfg.Profile.Headers {
+		headers.Set(key, value)
+	}
+
+	id := deliveryID()
+	switch e.cfg.Profile.Name {
+	case "github":
+		headers.Set("X-GitHub-Delivery", id)
+	case "standard_webhooks":
+		headers.Set("webhook-id", "msg_"+id)
+	}
+
+	return headers
+}
+
+func deliveryID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,106 @@
+package emulator
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lukeberry99/whook/internal/signing"
+)
+
+// Profile describes how a provider delivers webhooks. The schedules follow
+// each provider's public documentation, they're close approximations rather
+// than exact copies of the providers' internal behaviour.
+type Profile struct {
+	Name   string
+	Scheme signing.Scheme
+	// Timeout is how long the provider waits for a response
+	Timeout time.Duration
+	// Backoff is the delay before each retry, a failed delivery is given up
+	// on once every retry has been used
+	Backoff []time.Duration
+	// DisableAfter is the number of consecutive failed attempts after which
+	// the provider disables the endpoint, zero means it never does
+	DisableAfter int
+	Headers      map[string]string
+}
+
+var profiles = map[string]Profile{
+	"chargebee": {
+		Name:    "chargebee",
+		Scheme:  signing.SchemeChargebee,
+		Timeout: 20 * time.Second,
+		Backoff: []time.Duration{
+			2 * time.Minute,
+			6 * time.Minute,
+			30 * time.Minute,
+			1 * time.Hour,
+			5 * time.Hour,
+			1 * 24 * time.Hour,
+			2 * 24 * time.Hour,
+		},
+		// Chargebee doesn't document a failure count after which it disables
+		// an endpoint, it gives up on each event once its retries run out
+		DisableAfter: 0,
+		Headers:      map[string]string{"User-Agent": "ChargeBee"},
+	},
+	"stripe": {
+		Name:    "stripe",
+		Scheme:  signing.SchemeStripe,
+		Timeout: 20 * time.Second,
+		Backoff: []time.Duration{
+			1 * time.Hour,
+			2 * time.Hour,
+			4 * time.Hour,
+			8 * time.Hour,
+			16 * time.Hour,
+			24 * time.Hour,
+			24 * time.Hour,
+			24 * time.Hour,
+		},
+		DisableAfter: 40,
+		Headers:      map[string]string{"User-Agent": "Stripe/1.0 (+https://stripe.com/docs/webhooks)"},
+	},
+	"github": {
+		Name:    "github",
+		Scheme:  signing.SchemeGitHub,
+		Timeout: 10 * time.Second,
+		// GitHub doesn't retry failed deliveries automatically
+		Backoff:      nil,
+		DisableAfter: 0,
+		Headers:      map[string]string{"User-Agent": "GitHub-Hookshot/whook"},
+	},
+	"standard_webhooks": {
+		Name:    "standard_webhooks",
+		Scheme:  signing.SchemeStandardWebhooks,
+		Timeout: 15 * time.Second,
+		Backoff: []time.Duration{
+			5 * time.Second,
+			5 * time.Minute,
+			30 * time.Minute,
+			2 * time.Hour,
+			5 * time.Hour,
+			10 * time.Hour,
+			10 * time.Hour,
+		},
+		DisableAfter: 50,
+		Headers:      map[string]string{"User-Agent": "Svix-Webhooks/1.0"},
+	},
+}
+
+func LookupProfile(name string) (Profile, error) {
+	profile, ok := profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown provider %q, expected one of %v", name, ProfileNames())
+	}
+	return profile, nil
+}
+
+func ProfileNames() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,65 @@
+package eventtype
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/lukeberry99/whook/internal/config"
+)
+
+const (
+	SourceJSON   = "json"
+	SourceHeader = "header"
+)
+
+// Extract finds the event type of a webhook using the service's
+// event_type_source and event_type_location settings. For json sources the
+// location is a dotted path into the payload, for header sources it's the
+// header name. An empty string is returned when the type can't be found.
+func Extract(service config.ServiceConfig, headers http.Header, body []byte) string {
+	switch service.EventTypeSource {
+	case SourceHeader:
+		if headers == nil {
+			return ""
+		}
+		return headers.Get(service.EventTypeLocation)
+	case SourceJSON, "":
+		location := service.EventTypeLocation
+		if location == "" {
+			location = "event_type"
+		}
+
+		var payload interface{}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return ""
+		}
+		return lookup(payload, location)
+	default:
+		return ""
+	}
+}
+
+func lookup(payload interface{}, path string) string {
+	current := payload
+	for _, key := range strings.Split(path, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		current, ok = object[key]
+		if !ok {
+			return ""
+		}
+	}
+
+	switch value := current.(type) {
+	case string:
+		return value
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(value)
+	}
+}
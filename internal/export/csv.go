@@ -0,0 +1,128 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lukeberry99/whook/internal/jsonpath"
+)
+
+// DefaultColumns are exported when no columns are given
+var DefaultColumns = []string{"received_at", "service", "event_type", "filename"}
+
+// fields are the named columns, anything starting with $ is a JSON path into
+// the payload and header:<name> is a request header
+var fields = map[string]func(r request) string{
+	"received_at": func(r request) string { return r.receivedAt().Format(time.RFC3339) },
+	"service":     func(r request) string { return r.match.Item.ServiceName },
+	"filename":    func(r request) string { return r.match.Item.Filename },
+	"event_type":  func(r request) string { return r.match.EventType },
+	"tags":        func(r request) string { return strings.Join(r.match.Tags, " ") },
+	"method":      func(r request) string { return r.Method },
+	"path":        func(r request) string { return r.Path },
+	"query":       func(r request) string { return r.Query },
+	"status": func(r request) string {
+		if r.match.Event.Response == nil {
+			return ""
+		}
+		return strconv.Itoa(r.match.Event.Response.Status)
+	},
+}
+
+// ParseColumns splits a comma separated column list, trimming spaces
+func ParseColumns(spec string) []string {
+	var columns []string
+	for _, column := range strings.Split(spec, ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+type column struct {
+	name  string
+	value func(r request, payload interface{}) string
+}
+
+func parseColumn(name string) (column, error) {
+	if jsonpath.IsPath(name) {
+		path, err := jsonpath.Parse(name)
+		if err != nil {
+			return column{}, err
+		}
+		return column{name: name, value: func(r request, payload interface{}) string {
+			value, _ := path.Get(payload)
+			return jsonpath.Format(value)
+		}}, nil
+	}
+
+	if header, ok := strings.CutPrefix(name, "header:"); ok {
+		return column{name: name, value: func(r request, payload interface{}) string {
+			return strings.Join(r.Headers.Values(header), ", ")
+		}}, nil
+	}
+
+	field, ok := fields[name]
+	if !ok {
+		return column{}, fmt.Errorf("unknown column %q, use a field, header:<name> or a $ json path", name)
+	}
+	return column{name: name, value: func(r request, payload interface{}) string {
+		return field(r)
+	}}, nil
+}
+
+func writeCSV(w io.Writer, requests []request, opts Options) error {
+	return writeTable(w, ',', requests, opts)
+}
+
+func writeTSV(w io.Writer, requests []request, opts Options) error {
+	return writeTable(w, '\t', requests, opts)
+}
+
+func writeTable(w io.Writer, comma rune, requests []request, opts Options) error {
+	names := opts.Columns
+	if len(names) == 0 {
+		names = DefaultColumns
+	}
+
+	columns := make([]column, 0, len(names))
+	for _, name := range names {
+		c, err := parseColumn(name)
+		if err != nil {
+			return err
+		}
+		columns = append(columns, c)
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+
+	if err := writer.Write(names); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+
+	row := make([]string, len(columns))
+	for _, r := range requests {
+		// Payloads that aren't JSON still have their named columns filled
+		payload, _ := jsonpath.Decode(r.Body)
+
+		for i, c := range columns {
+			row[i] = c.value(r, payload)
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("writing row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("writing table: %w", err)
+	}
+
+	return nil
+}
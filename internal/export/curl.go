@@ -0,0 +1,51 @@
+package export
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// writeCurl writes a shell script with a curl command per request. Bodies are
+// piped to curl with --data-binary so they're sent byte for byte, bodies that
+// can't be quoted in a shell are decoded from base64.
+func writeCurl(w io.Writer, requests []request, opts Options) error {
+	var buf bytes.Buffer
+	buf.WriteString("#!/bin/sh\n")
+
+	for _, r := range requests {
+		fmt.Fprintf(&buf, "\n# %s, received %s\n", r.name(), r.receivedAt().Format(time.RFC3339))
+
+		if len(r.Body) > 0 {
+			if utf8.Valid(r.Body) && !bytes.ContainsRune(r.Body, 0) {
+				fmt.Fprintf(&buf, "printf '%%s' %s | ", shellQuote(string(r.Body)))
+			} else {
+				fmt.Fprintf(&buf, "printf '%%s' %s | base64 -d | ", shellQuote(base64.StdEncoding.EncodeToString(r.Body)))
+			}
+		}
+
+		fmt.Fprintf(&buf, "curl -sS -X %s %s", r.Method, shellQuote(r.URL))
+		for _, header := range r.sendableHeaders() {
+			fmt.Fprintf(&buf, " \\\n  -H %s", shellQuote(header[0]+": "+header[1]))
+		}
+		if len(r.Body) > 0 {
+			buf.WriteString(" \\\n  --data-binary @-")
+		}
+		buf.WriteString("\n")
+	}
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("writing curl commands: %w", err)
+	}
+
+	return nil
+}
+
+// shellQuote single quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
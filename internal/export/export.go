@@ -0,0 +1,177 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lukeberry99/whook/internal/query"
+	"github.com/lukeberry99/whook/internal/signing"
+)
+
+const (
+	FormatHAR     = "har"
+	FormatCurl    = "curl"
+	FormatPostman = "postman"
+	FormatJSONL   = "jsonl"
+	FormatCSV     = "csv"
+	FormatTSV     = "tsv"
+)
+
+var writers = map[string]func(w io.Writer, requests []request, opts Options) error{
+	FormatHAR:     writeHAR,
+	FormatCurl:    writeCurl,
+	FormatPostman: writePostman,
+	FormatJSONL:   writeJSONL,
+	FormatCSV:     writeCSV,
+	FormatTSV:     writeTSV,
+}
+
+var extensions = map[string]string{
+	FormatHAR:     ".har",
+	FormatCurl:    ".sh",
+	FormatPostman: ".postman_collection.json",
+	FormatJSONL:   ".jsonl",
+	FormatCSV:     ".csv",
+	FormatTSV:     ".tsv",
+}
+
+type Options struct {
+	// BaseURL is prefixed to each captured path, e.g. http://localhost:8080
+	BaseURL string
+	// Name names the Postman collection
+	Name string
+	// Columns are the csv and tsv columns, DefaultColumns when empty
+	Columns []string
+	// SignatureHeaders are custom HMAC signature headers by service, redacted
+	// along with the well known ones
+	SignatureHeaders map[string]string
+	// KeepSecrets writes credentials and signatures as they were captured,
+	// otherwise they're redacted since exports are meant to be shared
+	KeepSecrets bool
+}
+
+// Formats returns the supported export formats
+func Formats() []string {
+	formats := make([]string, 0, len(writers))
+	for format := range writers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Extension returns the file extension conventionally used for format
+func Extension(format string) string {
+	return extensions[format]
+}
+
+// Write exports matches to w in format, oldest first
+func Write(w io.Writer, format string, matches []query.Match, opts Options) error {
+	write, ok := writers[format]
+	if !ok {
+		return fmt.Errorf("unknown export format %q (available: %s)", format, strings.Join(Formats(), ", "))
+	}
+
+	if opts.BaseURL == "" {
+		opts.BaseURL = "http://localhost:8080"
+	}
+	opts.BaseURL = strings.TrimSuffix(opts.BaseURL, "/")
+	if opts.Name == "" {
+		opts.Name = "whook export"
+	}
+
+	requests := make([]request, 0, len(matches))
+	for i := len(matches) - 1; i >= 0; i-- {
+		requests = append(requests, newRequest(matches[i], opts))
+	}
+
+	return write(w, requests, opts)
+}
+
+// request is a match normalised into the request that was received, filling
+// in defaults for events stored before requests were captured
+type request struct {
+	match   query.Match
+	Method  string
+	URL     string
+	Path    string
+	Query   string
+	Headers http.Header
+	Body    []byte
+}
+
+func newRequest(match query.Match, opts Options) request {
+	event := match.Event
+
+	r := request{
+		match:   match,
+		Method:  event.Method,
+		Path:    event.Path,
+		Query:   event.Query,
+		Headers: event.Headers.Clone(),
+		Body:    event.Body(),
+	}
+	if r.Method == "" {
+		r.Method = http.MethodPost
+	}
+	if r.Path == "" {
+		r.Path = "/"
+	}
+	if r.Headers == nil {
+		r.Headers = http.Header{"Content-Type": []string{"application/json"}}
+	}
+	if !opts.KeepSecrets {
+		r.Headers = signing.RedactHeaders(r.Headers, false, opts.SignatureHeaders[match.Item.ServiceName])
+	}
+
+	r.URL = opts.BaseURL + r.Path
+	if r.Query != "" {
+		r.URL += "?" + r.Query
+	}
+
+	return r
+}
+
+// sortedKeys returns header names in a stable order
+func sortedKeys(headers http.Header) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sendableHeaders returns the headers a client should send when reproducing
+// the request, Content-Length is left for the client to calculate
+func (r request) sendableHeaders() [][2]string {
+	var headers [][2]string
+	for _, name := range sortedKeys(r.Headers) {
+		if strings.EqualFold(name, "Content-Length") {
+			continue
+		}
+		for _, value := range r.Headers[name] {
+			headers = append(headers, [2]string{name, value})
+		}
+	}
+	return headers
+}
+
+func (r request) receivedAt() time.Time {
+	return r.match.Event.ReceivedAt
+}
+
+func (r request) name() string {
+	name := strings.TrimSuffix(r.match.Item.Filename, ".json")
+	if r.match.EventType != "" {
+		name = r.match.EventType + " (" + name + ")"
+	}
+	if r.match.Item.ServiceName != "" {
+		name = r.match.Item.ServiceName + ": " + name
+	}
+	return name
+}
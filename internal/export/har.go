@@ -0,0 +1,183 @@
+package export
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lukeberry99/whook/internal/storage"
+	"github.com/lukeberry99/whook/internal/version"
+)
+
+// HAR 1.2, see http://www.softwareishard.com/blog/har-12-spec/
+type harLog struct {
+	Log struct {
+		Version string     `json:"version"`
+		Creator harCreator `json:"creator"`
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+func writeHAR(w io.Writer, requests []request, opts Options) error {
+	var har harLog
+	har.Log.Version = "1.2"
+	har.Log.Creator = harCreator{Name: "whook", Version: version.Version}
+	har.Log.Entries = make([]harEntry, 0, len(requests))
+
+	for _, r := range requests {
+		har.Log.Entries = append(har.Log.Entries, harEntry{
+			StartedDateTime: r.receivedAt().Format(time.RFC3339Nano),
+			Request:         harRequestFor(r),
+			Response:        harResponseFor(r),
+			Comment:         r.name(),
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(har); err != nil {
+		return fmt.Errorf("encoding har: %w", err)
+	}
+
+	return nil
+}
+
+func harRequestFor(r request) harRequest {
+	req := harRequest{
+		Method:      r.Method,
+		URL:         r.URL,
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(r.Headers),
+		QueryString: []harNameValue{},
+		HeadersSize: -1,
+		BodySize:    len(r.Body),
+	}
+
+	if values, err := url.ParseQuery(r.Query); err == nil {
+		for _, name := range sortedKeys(http.Header(values)) {
+			for _, value := range values[name] {
+				req.QueryString = append(req.QueryString, harNameValue{Name: name, Value: value})
+			}
+		}
+	}
+
+	if len(r.Body) > 0 {
+		req.PostData = &harPostData{MimeType: r.Headers.Get("Content-Type")}
+		// HAR has no encoding field for request bodies, but the common
+		// readers accept one the same way they do for response content
+		if utf8.Valid(r.Body) {
+			req.PostData.Text = string(r.Body)
+		} else {
+			req.PostData.Text = base64.StdEncoding.EncodeToString(r.Body)
+			req.PostData.Encoding = "base64"
+		}
+	}
+
+	return req
+}
+
+func harResponseFor(r request) harResponse {
+	res := harResponse{
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []harNameValue{},
+		HeadersSize: -1,
+	}
+
+	// Events stored before responses were recorded were acknowledged with a
+	// 200, whook didn't store events it rejected
+	stored := r.match.Event.Response
+	if stored == nil {
+		stored = &storage.StoredResponse{Status: http.StatusOK}
+	}
+
+	res.Status = stored.Status
+	res.StatusText = http.StatusText(stored.Status)
+	res.Headers = harHeaders(stored.Headers)
+	res.Content = harContent{
+		Size:     len(stored.Body),
+		MimeType: stored.Headers.Get("Content-Type"),
+		Text:     stored.Body,
+	}
+	res.BodySize = len(stored.Body)
+
+	return res
+}
+
+func harHeaders(headers http.Header) []harNameValue {
+	list := []harNameValue{}
+	for _, name := range sortedKeys(headers) {
+		for _, value := range headers[name] {
+			list = append(list, harNameValue{Name: name, Value: value})
+		}
+	}
+	return list
+}
@@ -0,0 +1,58 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/lukeberry99/whook/internal/storage"
+)
+
+// jsonlRecord is one line of a jsonl export. Body holds the payload as JSON
+// when it's valid JSON, RawBody always holds the exact bytes received.
+type jsonlRecord struct {
+	Service    string                  `json:"service,omitempty"`
+	Filename   string                  `json:"filename"`
+	EventType  string                  `json:"event_type,omitempty"`
+	Tags       []string                `json:"tags,omitempty"`
+	ReceivedAt time.Time               `json:"received_at"`
+	Method     string                  `json:"method"`
+	Path       string                  `json:"path"`
+	Query      string                  `json:"query,omitempty"`
+	Headers    http.Header             `json:"headers"`
+	Body       json.RawMessage         `json:"body,omitempty"`
+	RawBody    []byte                  `json:"raw_body"`
+	Response   *storage.StoredResponse `json:"response,omitempty"`
+}
+
+func writeJSONL(w io.Writer, requests []request, opts Options) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+
+	for _, r := range requests {
+		record := jsonlRecord{
+			Service:    r.match.Item.ServiceName,
+			Filename:   r.match.Item.Filename,
+			EventType:  r.match.EventType,
+			Tags:       r.match.Tags,
+			ReceivedAt: r.receivedAt(),
+			Method:     r.Method,
+			Path:       r.Path,
+			Query:      r.Query,
+			Headers:    r.Headers,
+			RawBody:    r.Body,
+			Response:   r.match.Event.Response,
+		}
+		if json.Valid(r.Body) {
+			record.Body = r.Body
+		}
+
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("encoding %s: %w", record.Filename, err)
+		}
+	}
+
+	return nil
+}
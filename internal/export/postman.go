@@ -0,0 +1,74 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const postmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+type postmanCollection struct {
+	Info struct {
+		Name   string `json:"name"`
+		Schema string `json:"schema"`
+	} `json:"info"`
+	Item []postmanItem `json:"item"`
+}
+
+type postmanItem struct {
+	Name    string         `json:"name"`
+	Request postmanRequest `json:"request"`
+}
+
+type postmanRequest struct {
+	Method string          `json:"method"`
+	Header []postmanHeader `json:"header"`
+	Body   *postmanBody    `json:"body,omitempty"`
+	URL    string          `json:"url"`
+}
+
+type postmanHeader struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type postmanBody struct {
+	Mode string `json:"mode"`
+	Raw  string `json:"raw"`
+}
+
+func writePostman(w io.Writer, requests []request, opts Options) error {
+	var collection postmanCollection
+	collection.Info.Name = opts.Name
+	collection.Info.Schema = postmanSchema
+	collection.Item = make([]postmanItem, 0, len(requests))
+
+	for _, r := range requests {
+		item := postmanItem{
+			Name: r.name(),
+			Request: postmanRequest{
+				Method: r.Method,
+				Header: []postmanHeader{},
+				URL:    r.URL,
+			},
+		}
+		for _, header := range r.sendableHeaders() {
+			item.Request.Header = append(item.Request.Header, postmanHeader{Key: header[0], Value: header[1]})
+		}
+		if len(r.Body) > 0 {
+			item.Request.Body = &postmanBody{Mode: "raw", Raw: string(r.Body)}
+		}
+
+		collection.Item = append(collection.Item, item)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(collection); err != nil {
+		return fmt.Errorf("encoding postman collection: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+// Package expr evaluates the expressions used for table columns and filters,
+// $ JSON paths and jq programs, against payloads decoded with
+// jsonpath.Decode.
+package expr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/itchyny/gojq"
+	"github.com/lukeberry99/whook/internal/jsonpath"
+)
+
+// timeout stops jq programs that don't finish, such as repeat(.)
+const timeout = 200 * time.Millisecond
+
+// maxResults caps how many values a jq program may produce
+const maxResults = 1000
+
+// Expr is a parsed $ JSON path or jq program
+type Expr struct {
+	source string
+	path   *jsonpath.Path
+	code   *gojq.Code
+}
+
+// IsExpr reports whether name is an expression rather than a name, it's one
+// when it starts with $ or, for jq, with .
+func IsExpr(name string) bool {
+	return jsonpath.IsPath(name) || strings.HasPrefix(name, ".")
+}
+
+// Parse reads a $ JSON path, or a jq program otherwise
+func Parse(source string) (*Expr, error) {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		return nil, fmt.Errorf("empty expression")
+	}
+
+	if jsonpath.IsPath(source) {
+		path, err := jsonpath.Parse(source)
+		if err != nil {
+			return nil, err
+		}
+		return &Expr{source: source, path: &path}, nil
+	}
+
+	query, err := gojq.Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("parsing jq expression: %w", err)
+	}
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return nil, fmt.Errorf("compiling jq expression: %w", err)
+	}
+	return &Expr{source: source, code: code}, nil
+}
+
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Eval returns every value the expression produces for doc. A JSON path
+// produces one value, or none when it isn't in doc.
+func (e *Expr) Eval(doc interface{}) ([]interface{}, error) {
+	if e.path != nil {
+		value, ok := e.path.Get(doc)
+		if !ok {
+			return nil, nil
+		}
+		return []interface{}{value}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var values []interface{}
+	iter := e.code.RunWithContext(ctx, doc)
+	for len(values) < maxResults {
+		value, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, isErr := value.(error); isErr {
+			return values, fmt.Errorf("running jq expression: %w", err)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// Format evaluates the expression for doc as a single line, values are joined
+// with commas and errors give an empty string
+func (e *Expr) Format(doc interface{}) string {
+	values, err := e.Eval(doc)
+	if err != nil {
+		return ""
+	}
+
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = jsonpath.Format(value)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Matches reports whether the expression produces a value for doc other than
+// false or null, as jq's select does
+func (e *Expr) Matches(doc interface{}) bool {
+	values, err := e.Eval(doc)
+	if err != nil {
+		return false
+	}
+	for _, value := range values {
+		if value != nil && value != false {
+			return true
+		}
+	}
+	return false
+}
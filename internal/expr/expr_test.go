@@ -0,0 +1,140 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/lukeberry99/whook/internal/jsonpath"
+)
+
+const payload = `{
+	"id": "evt_1",
+	"type": "invoice.paid",
+	"amount": 1250,
+	"live": false,
+	"customer": {"email": "a@example.com", "tags": ["vip", "eu"]},
+	"items": [{"sku": "a", "qty": 2}, {"sku": "b", "qty": 1}]
+}`
+
+func decode(t *testing.T) interface{} {
+	t.Helper()
+	doc, err := jsonpath.Decode([]byte(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestIsExpr(t *testing.T) {
+	tests := map[string]bool{
+		"$.id":            true,
+		"$":               true,
+		".type":           true,
+		".items | length": true,
+		"event_type":      false,
+		"time":            false,
+		"":                false,
+	}
+
+	for name, want := range tests {
+		if got := IsExpr(name); got != want {
+			t.Errorf("IsExpr(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, source := range []string{"", "   ", ".items[", "$.items[", "undefined_function(1)"} {
+		if _, err := Parse(source); err == nil {
+			t.Errorf("Parse(%q) succeeded", source)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	doc := decode(t)
+
+	tests := map[string]string{
+		"$.id":                              "evt_1",
+		"$.amount":                          "1250",
+		"$.live":                            "false",
+		"$.customer.tags[1]":                "eu",
+		"$.customer.tags":                   `["vip","eu"]`,
+		"$.missing":                         "",
+		".type":                             "invoice.paid",
+		" .customer.email ":                 "a@example.com",
+		".items[].sku":                      "a, b",
+		".items | length":                   "2",
+		"[.items[].qty] | add":              "3",
+		".amount / 100":                     "12.5",
+		".amount > 1000":                    "true",
+		".missing":                          "",
+		".type | split(\".\") | .[0]":       "invoice",
+		".id | error":                       "",
+		".customer | {email} | keys | .[0]": "email",
+	}
+
+	for source, want := range tests {
+		t.Run(source, func(t *testing.T) {
+			e, err := Parse(source)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got := e.Format(doc); got != want {
+				t.Errorf("Format = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	doc := decode(t)
+
+	tests := map[string]bool{
+		`.type == "invoice.paid"`:               true,
+		`.type == "invoice.failed"`:             false,
+		".amount >= 1000":                       true,
+		".live":                                 false,
+		".missing":                              false,
+		"$.id":                                  true,
+		"$.live":                                false,
+		"$.missing":                             false,
+		`.customer.tags | index("vip") != null`: true,
+		`.items[] | .qty > 1`:                   true,
+		`.items[] | .qty > 5`:                   false,
+		".id | error":                           false,
+	}
+
+	for source, want := range tests {
+		e, err := Parse(source)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", source, err)
+		}
+		if got := e.Matches(doc); got != want {
+			t.Errorf("Matches(%q) = %v, want %v", source, got, want)
+		}
+	}
+}
+
+func TestEvalCapsResults(t *testing.T) {
+	e, err := Parse("range(2000)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := e.Eval(decode(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != maxResults {
+		t.Errorf("Eval produced %d values, want %d", len(values), maxResults)
+	}
+}
+
+func TestEvalStopsRunawayPrograms(t *testing.T) {
+	e, err := Parse("def f: f; f")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Eval(decode(t)); err == nil {
+		t.Error("Eval of a program that never finishes succeeded")
+	}
+}
@@ -0,0 +1,228 @@
+package fixtures
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/lukeberry99/whook/internal/query"
+	"github.com/lukeberry99/whook/internal/signing"
+)
+
+const (
+	requestFile = "request.json"
+	bodyFile    = "body"
+)
+
+type Options struct {
+	// OutDir is the testdata directory fixtures are written to
+	OutDir string
+	// HelperPath is the Go file the request helper is written to, it's
+	// skipped when empty
+	HelperPath string
+	Package    string
+	// Signers re-sign fixtures by service, services without one keep the
+	// signature headers that were captured
+	Signers map[string]signing.Signer
+	// SignatureHeaders are custom HMAC signature headers by service, redacted
+	// along with the well known ones
+	SignatureHeaders map[string]string
+	// KeepSecrets writes credentials and captured signatures as they are,
+	// otherwise they're redacted. Signatures from Signers are always kept.
+	KeepSecrets bool
+}
+
+type fixtureRequest struct {
+	Method     string      `json:"method"`
+	Path       string      `json:"path"`
+	Query      string      `json:"query,omitempty"`
+	Headers    http.Header `json:"headers"`
+	Service    string      `json:"service,omitempty"`
+	EventType  string      `json:"event_type,omitempty"`
+	Tags       []string    `json:"tags,omitempty"`
+	ReceivedAt time.Time   `json:"received_at"`
+}
+
+// Export writes each match to OutDir/<service>/<event>/ as a request.json
+// with the method, path and headers alongside the raw body, and returns the
+// fixture names
+func Export(matches []query.Match, opts Options) ([]string, error) {
+	var names []string
+
+	for _, match := range matches {
+		service := match.Item.ServiceName
+		if service == "" {
+			service = "default"
+		}
+		name := service + "/" + strings.TrimSuffix(match.Item.Filename, filepath.Ext(match.Item.Filename))
+
+		dir := filepath.Join(opts.OutDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("creating fixture directory: %w", err)
+		}
+
+		event := match.Event
+		body := event.Body()
+
+		request := fixtureRequest{
+			Method:     event.Method,
+			Path:       event.Path,
+			Query:      event.Query,
+			Headers:    event.Headers.Clone(),
+			Service:    match.Item.ServiceName,
+			EventType:  match.EventType,
+			Tags:       match.Tags,
+			ReceivedAt: event.ReceivedAt,
+		}
+		if request.Method == "" {
+			request.Method = http.MethodPost
+		}
+		if request.Path == "" {
+			request.Path = "/"
+		}
+		if request.Headers == nil {
+			request.Headers = http.Header{"Content-Type": []string{"application/json"}}
+		}
+		if !opts.KeepSecrets {
+			request.Headers = signing.RedactHeaders(request.Headers, false, opts.SignatureHeaders[match.Item.ServiceName])
+		}
+
+		if signer, ok := opts.Signers[match.Item.ServiceName]; ok {
+			signed, err := http.NewRequest(request.Method, request.Path, bytes.NewReader(body))
+			if err != nil {
+				return nil, fmt.Errorf("building request for %s: %w", name, err)
+			}
+			signed.Header = request.Headers
+			if err := signer.Sign(signed, body, time.Now()); err != nil {
+				return nil, fmt.Errorf("signing %s: %w", name, err)
+			}
+			// Basic auth schemes sign with the password itself
+			if !opts.KeepSecrets {
+				request.Headers = signing.RedactHeaders(request.Headers, true)
+			}
+		}
+
+		meta, err := json.MarshalIndent(request, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("encoding %s: %w", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, requestFile), append(meta, '\n'), 0644); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, bodyFile), body, 0644); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", name, err)
+		}
+
+		names = append(names, name)
+	}
+
+	if opts.HelperPath != "" {
+		if err := writeHelper(opts, names); err != nil {
+			return nil, err
+		}
+	}
+
+	return names, nil
+}
+
+var helperTemplate = template.Must(template.New("helper").Parse(`// Code generated by whook fixtures export. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const whookFixturesDir = {{ printf "%q" .Dir }}
+
+// whookFixtures are the exported fixture names, each one can be passed to
+// newWhookFixtureRequest
+var whookFixtures = []string{
+{{- range .Names }}
+	{{ printf "%q" . }},
+{{- end }}
+}
+
+// newWhookFixtureRequest builds the captured request for a fixture, ready to
+// be served by a handler with httptest.NewRecorder
+func newWhookFixtureRequest(t testing.TB, name string) *http.Request {
+	t.Helper()
+
+	dir := filepath.Join(whookFixturesDir, filepath.FromSlash(name))
+
+	meta, err := os.ReadFile(filepath.Join(dir, "request.json"))
+	if err != nil {
+		t.Fatalf("reading fixture %s: %v", name, err)
+	}
+
+	var fixture struct {
+		Method  string      ` + "`json:\"method\"`" + `
+		Path    string      ` + "`json:\"path\"`" + `
+		Query   string      ` + "`json:\"query\"`" + `
+		Headers http.Header ` + "`json:\"headers\"`" + `
+	}
+	if err := json.Unmarshal(meta, &fixture); err != nil {
+		t.Fatalf("decoding fixture %s: %v", name, err)
+	}
+
+	body, err := os.ReadFile(filepath.Join(dir, "body"))
+	if err != nil {
+		t.Fatalf("reading fixture %s body: %v", name, err)
+	}
+
+	target := fixture.Path
+	if fixture.Query != "" {
+		target += "?" + fixture.Query
+	}
+
+	req := httptest.NewRequest(fixture.Method, target, bytes.NewReader(body))
+	for key, values := range fixture.Headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	return req
+}
+`))
+
+func writeHelper(opts Options, names []string) error {
+	helperDir := filepath.Dir(opts.HelperPath)
+	dir, err := filepath.Rel(helperDir, opts.OutDir)
+	if err != nil {
+		dir = opts.OutDir
+	}
+
+	var buf bytes.Buffer
+	if err := helperTemplate.Execute(&buf, map[string]interface{}{
+		"Package": opts.Package,
+		"Dir":     filepath.ToSlash(dir),
+		"Names":   names,
+	}); err != nil {
+		return fmt.Errorf("generating helper: %w", err)
+	}
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("formatting helper: %w", err)
+	}
+
+	if err := os.WriteFile(opts.HelperPath, source, 0644); err != nil {
+		return fmt.Errorf("writing helper: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,281 @@
+package fuzz
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Mutation is a variant of a captured payload with one thing broken. It's
+// only encoded when it's sent, so a run doesn't hold every variant in memory.
+type Mutation struct {
+	Name  string
+	value interface{}
+}
+
+// Body encodes the mutated payload
+func (m Mutation) Body() ([]byte, error) {
+	body, err := json.Marshal(m.value)
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s: %w", m.Name, err)
+	}
+	return body, nil
+}
+
+// Edge cases are written as JSON escapes so they stay readable in the source
+var unicodeEdgeCases = map[string]string{
+	"empty":           `""`,
+	"null byte":       `"\u0000"`,
+	"lone surrogate":  `"\ud800"`,
+	"rtl override":    `"\u202egnirts"`,
+	"emoji":           `"\ud83d\udc69\u200d\ud83d\udc67"`,
+	"combining marks": `"e\u0301\u0302\u0303\u0304\u0305"`,
+	"zero width":      `"a\u200bb\u200cc\u200dd"`,
+	"bom":             `"\ufeffvalue"`,
+}
+
+var outOfRangeNumbers = map[string]string{
+	"huge number":     `1e400`,
+	"negative huge":   `-1e400`,
+	"int64 overflow":  `9223372036854775808`,
+	"tiny number":     `1e-400`,
+	"negative number": `-1`,
+	"fractional":      `0.5`,
+}
+
+const hugeStringLength = 1 << 20
+
+// hugeString is only built when a mutation using it is encoded
+type hugeString int
+
+func (h hugeString) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strings.Repeat("A", int(h)))
+}
+
+// Generate returns mutations of body, at most limit of them when limit > 0.
+// Paths are visited in a stable order so runs are reproducible.
+func Generate(body []byte, limit int) ([]Mutation, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var root interface{}
+	if err := decoder.Decode(&root); err != nil {
+		return nil, fmt.Errorf("decoding payload: %w", err)
+	}
+
+	var mutations []Mutation
+	add := func(name string, mutated interface{}) bool {
+		mutations = append(mutations, Mutation{Name: name, value: mutated})
+		return limit <= 0 || len(mutations) < limit
+	}
+
+	walk(root, nil, func(path []string, value interface{}) bool {
+		label := "$"
+		if len(path) > 0 {
+			label = "$." + strings.Join(path, ".")
+		}
+
+		for _, m := range valueMutations(value) {
+			if !add(fmt.Sprintf("%s: %s", label, m.Name), replaceAt(root, path, m.value)) {
+				return false
+			}
+		}
+
+		if len(path) > 0 {
+			if !add(fmt.Sprintf("%s: removed", label), removeAt(root, path)) {
+				return false
+			}
+		}
+
+		if object, ok := value.(map[string]interface{}); ok && len(object) > 0 {
+			if !add(fmt.Sprintf("%s: duplicated key", label), replaceAt(root, path, duplicateKey(object))) {
+				return false
+			}
+		}
+
+		return true
+	})
+
+	return mutations, nil
+}
+
+type valueMutation struct {
+	Name  string
+	value interface{}
+}
+
+func valueMutations(value interface{}) []valueMutation {
+	mutations := []valueMutation{{Name: "null", value: nil}}
+
+	switch v := value.(type) {
+	case string:
+		mutations = append(mutations,
+			valueMutation{Name: "wrong type (number)", value: json.Number("12345")},
+			valueMutation{Name: "wrong type (object)", value: map[string]interface{}{"value": v}},
+			valueMutation{Name: "huge string", value: hugeString(hugeStringLength)},
+		)
+		for _, name := range sortedKeys(unicodeEdgeCases) {
+			mutations = append(mutations, valueMutation{Name: "unicode " + name, value: json.RawMessage(unicodeEdgeCases[name])})
+		}
+	case json.Number:
+		mutations = append(mutations,
+			valueMutation{Name: "wrong type (string)", value: v.String()},
+			valueMutation{Name: "wrong type (bool)", value: true},
+		)
+		for _, name := range sortedKeys(outOfRangeNumbers) {
+			mutations = append(mutations, valueMutation{Name: name, value: json.RawMessage(outOfRangeNumbers[name])})
+		}
+	case bool:
+		mutations = append(mutations,
+			valueMutation{Name: "wrong type (string)", value: fmt.Sprint(v)},
+			valueMutation{Name: "wrong type (number)", value: json.Number("1")},
+		)
+	case map[string]interface{}:
+		mutations = append(mutations,
+			valueMutation{Name: "wrong type (array)", value: []interface{}{v}},
+			valueMutation{Name: "empty object", value: map[string]interface{}{}},
+		)
+	case []interface{}:
+		mutations = append(mutations,
+			valueMutation{Name: "wrong type (object)", value: map[string]interface{}{}},
+			valueMutation{Name: "empty array", value: []interface{}{}},
+		)
+	}
+
+	return mutations
+}
+
+// walk visits every value depth first, stopping when visit returns false
+func walk(value interface{}, path []string, visit func(path []string, value interface{}) bool) bool {
+	if !visit(path, value) {
+		return false
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			if !walk(v[key], appendPath(path, key), visit) {
+				return false
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			if !walk(item, appendPath(path, fmt.Sprint(i)), visit) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func appendPath(path []string, key string) []string {
+	next := make([]string, len(path), len(path)+1)
+	copy(next, path)
+	return append(next, key)
+}
+
+// replaceAt returns a copy of root with the value at path swapped out. Only
+// the containers along the path are copied, the rest is shared.
+func replaceAt(root interface{}, path []string, replacement interface{}) interface{} {
+	if len(path) == 0 {
+		return replacement
+	}
+
+	switch v := root.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			copied[key] = value
+		}
+		copied[path[0]] = replaceAt(v[path[0]], path[1:], replacement)
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		copy(copied, v)
+		var index int
+		fmt.Sscan(path[0], &index)
+		copied[index] = replaceAt(v[index], path[1:], replacement)
+		return copied
+	default:
+		return root
+	}
+}
+
+func removeAt(root interface{}, path []string) interface{} {
+	parentPath, last := path[:len(path)-1], path[len(path)-1]
+
+	var parent interface{} = root
+	for _, key := range parentPath {
+		switch v := parent.(type) {
+		case map[string]interface{}:
+			parent = v[key]
+		case []interface{}:
+			var index int
+			fmt.Sscan(key, &index)
+			parent = v[index]
+		}
+	}
+
+	var removed interface{}
+	switch v := parent.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if key != last {
+				copied[key] = value
+			}
+		}
+		removed = copied
+	case []interface{}:
+		var index int
+		fmt.Sscan(last, &index)
+		copied := make([]interface{}, 0, len(v))
+		copied = append(copied, v[:index]...)
+		removed = append(copied, v[index+1:]...)
+	default:
+		return root
+	}
+
+	return replaceAt(root, parentPath, removed)
+}
+
+// duplicateKey encodes object with its first key repeated at the end with a
+// different value, which encoding/json can't produce on its own
+func duplicateKey(object map[string]interface{}) json.RawMessage {
+	keys := sortedKeys(object)
+
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		encodedKey, _ := json.Marshal(key)
+		encodedValue, err := json.Marshal(object[key])
+		if err != nil {
+			encodedValue = []byte("null")
+		}
+		buf.Write(encodedKey)
+		buf.WriteString(":")
+		buf.Write(encodedValue)
+	}
+
+	encodedKey, _ := json.Marshal(keys[0])
+	buf.WriteString(",")
+	buf.Write(encodedKey)
+	buf.WriteString(`:"whook-duplicate"}`)
+
+	return buf.Bytes()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,218 @@
+package generate
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/lukeberry99/whook/internal/config"
+)
+
+//go:embed templates
+var builtins embed.FS
+
+const templateSuffix = ".json.tmpl"
+
+type Template struct {
+	Service string
+	Name    string
+	// Source is "builtin" or the path the template was loaded from
+	Source string
+	text   string
+}
+
+// DefaultDir is where user templates for a service live when the service
+// doesn't configure a templates_dir
+func DefaultDir(service string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "whook", "templates", service)
+}
+
+// Dir returns the templates directory for a service
+func Dir(serviceName string, service config.ServiceConfig) string {
+	if service.TemplatesDir != "" {
+		return service.TemplatesDir
+	}
+	return DefaultDir(serviceName)
+}
+
+// List returns the built-in templates for a service merged with any found in
+// dir, user templates replace built-ins with the same name
+func List(service, dir string) ([]Template, error) {
+	templates := map[string]Template{}
+
+	entries, err := fs.ReadDir(builtins, path.Join("templates", service))
+	if err == nil {
+		for _, entry := range entries {
+			if !strings.HasSuffix(entry.Name(), templateSuffix) {
+				continue
+			}
+			data, err := builtins.ReadFile(path.Join("templates", service, entry.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("reading built-in template %s: %w", entry.Name(), err)
+			}
+			name := strings.TrimSuffix(entry.Name(), templateSuffix)
+			templates[name] = Template{Service: service, Name: name, Source: "builtin", text: string(data)}
+		}
+	}
+
+	if dir != "" {
+		entries, err := os.ReadDir(dir)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("reading templates directory: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), templateSuffix) {
+				continue
+			}
+			fullPath := filepath.Join(dir, entry.Name())
+			data, err := os.ReadFile(fullPath)
+			if err != nil {
+				return nil, fmt.Errorf("reading template %s: %w", fullPath, err)
+			}
+			name := strings.TrimSuffix(entry.Name(), templateSuffix)
+			templates[name] = Template{Service: service, Name: name, Source: fullPath, text: string(data)}
+		}
+	}
+
+	list := make([]Template, 0, len(templates))
+	for _, t := range templates {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list, nil
+}
+
+func Find(service, dir, name string) (Template, error) {
+	templates, err := List(service, dir)
+	if err != nil {
+		return Template{}, err
+	}
+
+	for _, t := range templates {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+
+	return Template{}, fmt.Errorf("no template named %q for service %q", name, service)
+}
+
+// Render executes the template with vars available as dot, for example
+// {{ .customer_id }}. The output must be valid JSON and is returned compacted.
+func (t Template) Render(vars map[string]string) ([]byte, error) {
+	if vars == nil {
+		vars = map[string]string{}
+	}
+
+	tmpl, err := template.New(t.Name).
+		Option("missingkey=zero").
+		Funcs(funcs()).
+		Parse(t.text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing template %s: %w", t.Name, err)
+	}
+
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, vars); err != nil {
+		return nil, fmt.Errorf("executing template %s: %w", t.Name, err)
+	}
+
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, rendered.Bytes()); err != nil {
+		return nil, fmt.Errorf("template %s didn't produce valid JSON: %w", t.Name, err)
+	}
+
+	return compacted.Bytes(), nil
+}
+
+var (
+	firstNames = []string{"Ada", "Grace", "Alan", "Linus", "Margaret", "Ken", "Barbara", "Dennis", "Frances", "Edsger"}
+	lastNames  = []string{"Lovelace", "Hopper", "Turing", "Torvalds", "Hamilton", "Thompson", "Liskov", "Ritchie", "Allen", "Dijkstra"}
+	domains    = []string{"example.com", "example.org", "example.net"}
+	currencies = []string{"USD", "GBP", "EUR"}
+)
+
+const idAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+func funcs() template.FuncMap {
+	return template.FuncMap{
+		// id "sub" gives a Chargebee style identifier such as sub_16CRibSCi4yFt2Ab
+		"id": func(prefix string) string {
+			var b strings.Builder
+			for i := 0; i < 16; i++ {
+				b.WriteByte(idAlphabet[rand.IntN(len(idAlphabet))])
+			}
+			if prefix == "" {
+				return b.String()
+			}
+			return prefix + "_" + b.String()
+		},
+		"uuid": func() string {
+			b := make([]byte, 16)
+			for i := range b {
+				b[i] = byte(rand.IntN(256))
+			}
+			b[6] = (b[6] & 0x0f) | 0x40
+			b[8] = (b[8] & 0x3f) | 0x80
+			return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+		},
+		"invoiceNumber": func() string {
+			return fmt.Sprintf("%d", 1000+rand.IntN(9000))
+		},
+		"now": func() int64 {
+			return time.Now().Unix()
+		},
+		"timestamp": func() string {
+			return time.Now().UTC().Format(time.RFC3339)
+		},
+		"add": func(a, b int64) int64 {
+			return a + b
+		},
+		// amount is in minor units, as Chargebee sends it
+		"amount": func(min, max int) int {
+			if max <= min {
+				return min
+			}
+			return min + rand.IntN(max-min)
+		},
+		"firstName": func() string {
+			return firstNames[rand.IntN(len(firstNames))]
+		},
+		"lastName": func() string {
+			return lastNames[rand.IntN(len(lastNames))]
+		},
+		"email": func(names ...string) string {
+			local := strings.ToLower(strings.Join(names, "."))
+			if local == "" {
+				local = fmt.Sprintf("user%d", rand.IntN(100000))
+			}
+			return fmt.Sprintf("%s+%d@%s", local, rand.IntN(10000), domains[rand.IntN(len(domains))])
+		},
+		"currency": func() string {
+			return currencies[rand.IntN(len(currencies))]
+		},
+		"pick": func(options ...string) string {
+			if len(options) == 0 {
+				return ""
+			}
+			return options[rand.IntN(len(options))]
+		},
+	}
+}
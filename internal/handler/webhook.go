@@ -8,10 +8,13 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/lukeberry99/whook/internal/config"
+	"github.com/lukeberry99/whook/internal/eventtype"
+	"github.com/lukeberry99/whook/internal/hooks"
 	"github.com/lukeberry99/whook/internal/storage"
 )
 
-func WebhookHandler(w http.ResponseWriter, r *http.Request, store storage.WebhookStorage, logChan chan<- string) {
+func WebhookHandler(w http.ResponseWriter, r *http.Request, cfg *config.Config, store storage.WebhookStorage, runner *hooks.Runner, logChan chan<- string) {
 	rawBody, err := io.ReadAll(r.Body)
 	if err != nil {
 		http.Error(w, "Error reading request body", http.StatusBadRequest)
@@ -42,6 +45,12 @@ func WebhookHandler(w http.ResponseWriter, r *http.Request, store storage.Webhoo
 	event := &storage.WebhookEvent{
 		ReceivedAt: receivedAt,
 		RawEvent:   rawJSON,
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		Query:      r.URL.RawQuery,
+		Headers:    r.Header,
+		// Whook only stores webhooks it's about to acknowledge
+		Response: &storage.StoredResponse{Status: http.StatusOK},
 	}
 
 	filename, err := store.Store(event, rawBody)
@@ -53,5 +62,16 @@ func WebhookHandler(w http.ResponseWriter, r *http.Request, store storage.Webhoo
 
 	logChan <- fmt.Sprintf("Webhook processed: %s", filename)
 
+	if runner != nil {
+		service := store.SelectedService()
+		runner.Dispatch(hooks.Event{
+			Service:    service,
+			EventType:  eventtype.Extract(cfg.Services[service], r.Header, rawBody),
+			Filename:   filename,
+			ReceivedAt: receivedAt,
+			Body:       rawBody,
+		})
+	}
+
 	w.WriteHeader(http.StatusOK)
 }
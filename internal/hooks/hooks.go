@@ -0,0 +1,197 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lukeberry99/whook/internal/config"
+)
+
+const (
+	defaultTimeout        = 30 * time.Second
+	defaultMaxConcurrency = 4
+	// Long outputs are truncated so a chatty script can't flood the log pane
+	maxOutputLines = 20
+)
+
+type Event struct {
+	Service    string
+	EventType  string
+	Filename   string
+	ReceivedAt time.Time
+	Body       []byte
+}
+
+// Runner executes the configured hook commands for incoming webhooks
+type Runner struct {
+	cfg     *config.Config
+	slots   chan struct{}
+	logChan chan<- string
+
+	// ctx is cancelled by Stop, which kills the running hooks
+	ctx     context.Context
+	cancel  context.CancelFunc
+	mu      sync.Mutex
+	stopped bool
+	running sync.WaitGroup
+}
+
+func NewRunner(cfg *config.Config, logChan chan<- string) *Runner {
+	maxConcurrency := cfg.Hooks.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = defaultMaxConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Runner{
+		cfg:     cfg,
+		slots:   make(chan struct{}, maxConcurrency),
+		logChan: logChan,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Dispatch starts every hook that matches the event in the background
+func (r *Runner) Dispatch(event Event) {
+	service, ok := r.cfg.Services[event.Service]
+	if !ok {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopped {
+		return
+	}
+
+	for _, hook := range service.Hooks {
+		if !matches(hook, event.EventType) {
+			continue
+		}
+
+		r.running.Add(1)
+		go func() {
+			defer r.running.Done()
+			r.run(hook, event)
+		}()
+	}
+}
+
+// Stop kills the running hooks and waits for them to finish, so nothing is
+// logged once it returns and the log channel can be closed
+func (r *Runner) Stop() {
+	r.mu.Lock()
+	r.stopped = true
+	r.mu.Unlock()
+
+	r.cancel()
+	r.running.Wait()
+}
+
+// log drops the message once the runner is stopped, no one may be reading
+// the log channel any more
+func (r *Runner) log(msg string) {
+	select {
+	case r.logChan <- msg:
+	case <-r.ctx.Done():
+	}
+}
+
+func matches(hook config.HookConfig, eventType string) bool {
+	if len(hook.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range hook.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Runner) run(hook config.HookConfig, event Event) {
+	select {
+	case r.slots <- struct{}{}:
+	case <-r.ctx.Done():
+		return
+	}
+	defer func() { <-r.slots }()
+
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(r.ctx, timeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, hook.Command)
+	cmd.Stdin = bytes.NewReader(event.Body)
+	cmd.Env = append(os.Environ(),
+		"WHOOK_SERVICE="+event.Service,
+		"WHOOK_EVENT_TYPE="+event.EventType,
+		"WHOOK_EVENT_FILE="+event.Filename,
+		"WHOOK_RECEIVED_AT="+event.ReceivedAt.Format(time.RFC3339),
+	)
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	// Child processes can hold the output pipes open after the shell is killed
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err := cmd.Run()
+	duration := time.Since(start).Round(time.Millisecond)
+
+	exitCode := 0
+	var exitErr *exec.ExitError
+	switch {
+	case r.ctx.Err() != nil:
+		return
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		r.log(fmt.Sprintf("Hook %q for %s timed out after %s", hook.Command, event.Service, timeout))
+		return
+	case errors.As(err, &exitErr):
+		exitCode = exitErr.ExitCode()
+	case err != nil:
+		r.log(fmt.Sprintf("Hook %q for %s failed to run: %v", hook.Command, event.Service, err))
+		return
+	}
+
+	r.log(fmt.Sprintf("Hook %q for %s exited %d in %s", hook.Command, event.Service, exitCode, duration))
+	for _, line := range outputLines(output.String()) {
+		r.log(fmt.Sprintf("  [%s] %s", event.Service, line))
+	}
+}
+
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+func outputLines(output string) []string {
+	output = strings.TrimRight(output, "\n")
+	if output == "" {
+		return nil
+	}
+
+	lines := strings.Split(output, "\n")
+	if len(lines) > maxOutputLines {
+		omitted := len(lines) - maxOutputLines
+		lines = append(lines[:maxOutputLines], fmt.Sprintf("... %d more lines", omitted))
+	}
+	return lines
+}
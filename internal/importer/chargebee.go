@@ -0,0 +1,44 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// parseChargebee reads the response of Chargebee's list events API. Webhooks
+// carry the same event object, so each one is imported as the payload that
+// was delivered when it occurred.
+func parseChargebee(data []byte) ([]Event, error) {
+	var response struct {
+		List []struct {
+			Event json.RawMessage `json:"event"`
+		} `json:"list"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("decoding events list: %w", err)
+	}
+
+	events := make([]Event, 0, len(response.List))
+	for i, entry := range response.List {
+		if entry.Event == nil {
+			return nil, fmt.Errorf("entry %d has no event", i+1)
+		}
+
+		var meta struct {
+			OccurredAt int64 `json:"occurred_at"`
+		}
+		if err := json.Unmarshal(entry.Event, &meta); err != nil {
+			return nil, fmt.Errorf("decoding event %d: %w", i+1, err)
+		}
+
+		event := Event{Body: entry.Event}
+		if meta.OccurredAt > 0 {
+			event.ReceivedAt = time.Unix(meta.OccurredAt, 0)
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
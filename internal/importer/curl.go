@@ -0,0 +1,420 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// curlReceivedAt finds the time in the comments whook export --format curl
+// writes above each command
+var curlReceivedAt = regexp.MustCompile(`received (\S+)\s*$`)
+
+// curlArgFlags are the curl options that take an argument but don't affect
+// the request whook stores
+var curlArgFlags = map[string]bool{
+	"-o": true, "--output": true, "-b": true, "--cookie": true, "-c": true, "--cookie-jar": true,
+	"-e": true, "--referer": true, "-m": true, "--max-time": true, "--connect-timeout": true,
+	"-x": true, "--proxy": true, "-w": true, "--write-out": true, "--retry": true,
+	"--cacert": true, "--cert": true, "--key": true, "-r": true, "--range": true,
+	"--resolve": true, "--connect-to": true,
+}
+
+// curlShortValueFlags are the short options whose value may be attached, as in
+// -XPOST
+var curlShortValueFlags = map[string]bool{
+	"-X": true, "-H": true, "-d": true, "-u": true, "-A": true,
+	"-o": true, "-b": true, "-c": true, "-e": true, "-m": true, "-x": true, "-w": true, "-r": true,
+}
+
+// parseCurl reads curl commands, such as the ones browsers copy or whook
+// export --format curl writes. Bodies piped in with printf are supported so
+// exported scripts import byte for byte.
+func parseCurl(data []byte) ([]Event, error) {
+	commands, err := shellCommands(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	for _, command := range commands {
+		for i, words := range command.pipeline {
+			if len(words) == 0 || words[0] != "curl" {
+				continue
+			}
+
+			event, err := curlEvent(words[1:], pipedInput(command.pipeline[:i]))
+			if err != nil {
+				return nil, fmt.Errorf("curl command %d: %w", len(events)+1, err)
+			}
+			if m := curlReceivedAt.FindStringSubmatch(command.comment); m != nil {
+				if receivedAt, err := time.Parse(time.RFC3339, m[1]); err == nil {
+					event.ReceivedAt = receivedAt
+				}
+			}
+
+			events = append(events, event)
+		}
+	}
+
+	if len(events) == 0 {
+		return nil, fmt.Errorf("no curl commands found")
+	}
+
+	return events, nil
+}
+
+func curlEvent(args []string, stdin []byte) (Event, error) {
+	event := Event{Headers: http.Header{}}
+	var target string
+	var data [][]byte
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		// --flag=value and -Xvalue are the same as passing the value as the
+		// next argument
+		var inline *string
+		if strings.HasPrefix(arg, "--") {
+			if name, value, ok := strings.Cut(arg, "="); ok {
+				arg, inline = name, &value
+			}
+		} else if len(arg) > 2 && arg[0] == '-' && curlShortValueFlags[arg[:2]] {
+			value := arg[2:]
+			arg, inline = arg[:2], &value
+		}
+
+		next := func() (string, error) {
+			if inline != nil {
+				return *inline, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%s needs an argument", arg)
+			}
+			i++
+			return args[i], nil
+		}
+
+		switch arg {
+		case "-X", "--request":
+			value, err := next()
+			if err != nil {
+				return event, err
+			}
+			event.Method = value
+		case "-H", "--header":
+			value, err := next()
+			if err != nil {
+				return event, err
+			}
+			name, headerValue, ok := strings.Cut(value, ":")
+			if !ok {
+				return event, fmt.Errorf("invalid header %q", value)
+			}
+			event.Headers.Add(strings.TrimSpace(name), strings.TrimSpace(headerValue))
+		case "-d", "--data", "--data-raw", "--data-binary", "--data-ascii", "--data-urlencode", "--json":
+			value, err := next()
+			if err != nil {
+				return event, err
+			}
+			body, err := curlData(arg, value, stdin)
+			if err != nil {
+				return event, err
+			}
+			data = append(data, body)
+			if arg == "--json" && event.Headers.Get("Content-Type") == "" {
+				event.Headers.Set("Content-Type", "application/json")
+			}
+		case "-u", "--user":
+			value, err := next()
+			if err != nil {
+				return event, err
+			}
+			event.Headers.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(value)))
+		case "-A", "--user-agent":
+			value, err := next()
+			if err != nil {
+				return event, err
+			}
+			event.Headers.Set("User-Agent", value)
+		case "--url":
+			value, err := next()
+			if err != nil {
+				return event, err
+			}
+			target = value
+		default:
+			if curlArgFlags[arg] {
+				if inline == nil {
+					i++
+				}
+				continue
+			}
+			// Anything else starting with a dash is a switch such as -sS
+			if strings.HasPrefix(arg, "-") {
+				continue
+			}
+			target = arg
+		}
+	}
+
+	if target == "" {
+		return event, fmt.Errorf("no url")
+	}
+
+	path, query, err := splitURL(target)
+	if err != nil {
+		return event, err
+	}
+	event.Path = path
+	event.Query = query
+
+	// curl joins repeated data options with &
+	event.Body = bytes.Join(data, []byte("&"))
+
+	if event.Method == "" {
+		event.Method = http.MethodGet
+		if len(data) > 0 {
+			event.Method = http.MethodPost
+		}
+	}
+
+	return event, nil
+}
+
+// curlData resolves a data option's value, @- reads stdin and @file reads a
+// file, except for --data-raw which is always literal
+func curlData(flag, value string, stdin []byte) ([]byte, error) {
+	if flag == "--data-raw" || !strings.HasPrefix(value, "@") {
+		return []byte(value), nil
+	}
+
+	if value == "@-" {
+		if stdin == nil {
+			return nil, fmt.Errorf("%s reads stdin, which only printf pipes are supported for", flag)
+		}
+		return stdin, nil
+	}
+
+	body, err := os.ReadFile(value[1:])
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", value[1:], err)
+	}
+	return body, nil
+}
+
+// pipedInput returns what the commands before curl in a pipeline write, for
+// printf followed by an optional base64 -d
+func pipedInput(pipeline [][]string) []byte {
+	var output []byte
+	for _, words := range pipeline {
+		switch {
+		case len(words) >= 2 && words[0] == "printf":
+			// Only the '%s' form is written verbatim, other formats would
+			// need interpreting
+			if len(words) == 3 && words[1] == "%s" {
+				output = []byte(words[2])
+			} else {
+				output = []byte(words[1])
+			}
+		case len(words) >= 2 && words[0] == "echo":
+			output = []byte(strings.Join(words[1:], " ") + "\n")
+		case len(words) == 2 && words[0] == "base64" && (words[1] == "-d" || words[1] == "--decode"):
+			decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(output)))
+			if err != nil {
+				return nil
+			}
+			output = decoded
+		default:
+			return nil
+		}
+	}
+	return output
+}
+
+type shellCommand struct {
+	// comment is the last comment line before the command
+	comment  string
+	pipeline [][]string
+}
+
+// shellCommands splits a shell script into commands of piped words, handling
+// the quoting curl commands are written with. It's not a full shell, but
+// covers single quotes, double quotes, $'...' strings and line continuations.
+func shellCommands(script string) ([]shellCommand, error) {
+	var commands []shellCommand
+	var current shellCommand
+	var words []string
+	var word strings.Builder
+	inWord := false
+	comment := ""
+
+	endWord := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+	endPipe := func() {
+		endWord()
+		if len(words) > 0 {
+			current.pipeline = append(current.pipeline, words)
+			words = nil
+		}
+	}
+	endCommand := func() {
+		endPipe()
+		if len(current.pipeline) > 0 {
+			current.comment = comment
+			commands = append(commands, current)
+			comment = ""
+		}
+		current = shellCommand{}
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+
+		switch {
+		case c == '\\' && i+1 < len(script) && script[i+1] == '\n':
+			i++
+		case c == '\\' && i+1 < len(script) && script[i+1] == '\r' && i+2 < len(script) && script[i+2] == '\n':
+			i += 2
+		case c == '\\' && i+1 < len(script):
+			word.WriteByte(script[i+1])
+			inWord = true
+			i++
+		case c == '\'':
+			end := strings.IndexByte(script[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			word.WriteString(script[i+1 : i+1+end])
+			inWord = true
+			i += end + 1
+		case c == '$' && i+1 < len(script) && script[i+1] == '\'':
+			value, n, err := ansiCString(script[i+2:])
+			if err != nil {
+				return nil, err
+			}
+			word.WriteString(value)
+			inWord = true
+			i += n + 1
+		case c == '"':
+			n, err := doubleQuoted(script[i+1:], &word)
+			if err != nil {
+				return nil, err
+			}
+			inWord = true
+			i += n
+		case c == '#' && !inWord:
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				end = len(script) - i
+			}
+			if len(words) == 0 && len(current.pipeline) == 0 {
+				comment = strings.TrimSpace(script[i+1 : i+end])
+			}
+			i += end - 1
+		case c == '|' && i+1 < len(script) && script[i+1] == '|':
+			endCommand()
+			i++
+		case c == '|':
+			endPipe()
+		case c == '&' && i+1 < len(script) && script[i+1] == '&':
+			endCommand()
+			i++
+		case c == ';' || c == '\n':
+			endCommand()
+		case c == ' ' || c == '\t' || c == '\r':
+			endWord()
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	endCommand()
+
+	return commands, nil
+}
+
+// doubleQuoted reads a double quoted string up to its closing quote, returning
+// how many bytes were consumed including the quote
+func doubleQuoted(s string, word *strings.Builder) (int, error) {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return i + 1, nil
+		case '\\':
+			if i+1 < len(s) && strings.IndexByte("$`\"\\\n", s[i+1]) >= 0 {
+				if s[i+1] != '\n' {
+					word.WriteByte(s[i+1])
+				}
+				i++
+				continue
+			}
+		}
+		word.WriteByte(s[i])
+	}
+	return 0, fmt.Errorf("unterminated double quote")
+}
+
+// ansiCString decodes the body of a $'...' string, returning how many bytes
+// were consumed including the closing quote
+func ansiCString(s string) (string, int, error) {
+	var out strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\'' {
+			return out.String(), i + 1, nil
+		}
+		if c != '\\' || i+1 >= len(s) {
+			out.WriteByte(c)
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 'n':
+			out.WriteByte('\n')
+		case 'r':
+			out.WriteByte('\r')
+		case 't':
+			out.WriteByte('\t')
+		case 'x', 'u', 'U':
+			digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[s[i]]
+			n := hexDigits(s[i+1:], digits)
+			value, err := strconv.ParseUint(s[i+1:i+1+n], 16, 32)
+			if err != nil {
+				return "", 0, fmt.Errorf("invalid escape \\%c in $' quote", s[i])
+			}
+			if s[i] == 'x' {
+				out.WriteByte(byte(value))
+			} else {
+				out.WriteRune(rune(value))
+			}
+			i += n
+		default:
+			// \\, \' and \" along with anything unknown keep the character
+			out.WriteByte(s[i])
+		}
+	}
+
+	return "", 0, fmt.Errorf("unterminated $' quote")
+}
+
+func hexDigits(s string, max int) int {
+	n := 0
+	for n < len(s) && n < max && strings.IndexByte("0123456789abcdefABCDEF", s[n]) >= 0 {
+		n++
+	}
+	return n
+}
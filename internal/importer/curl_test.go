@@ -0,0 +1,170 @@
+package importer
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseCurl(t *testing.T) {
+	tests := []struct {
+		name       string
+		script     string
+		method     string
+		path       string
+		query      string
+		headers    http.Header
+		body       string
+		receivedAt time.Time
+	}{
+		{
+			name:   "browser copy",
+			script: "curl 'https://example.com/hooks?x=1' \\\n  -H 'Content-Type: application/json' \\\n  --data-raw '{\"id\":1}' \\\n  --compressed",
+			method: "POST",
+			path:   "/hooks",
+			query:  "x=1",
+			headers: http.Header{
+				"Content-Type": {"application/json"},
+			},
+			body: `{"id":1}`,
+		},
+		{
+			name:   "exported with printf",
+			script: "#!/bin/sh\n\n# stripe/1.json, received 2024-03-01T10:00:00Z\nprintf '%s' '{\"name\":\"it'\\''s\"}' | curl -sS -X PUT 'http://localhost:8080/stripe' \\\n  -H 'X-Id: 1' \\\n  --data-binary @-\n",
+			method: "PUT",
+			path:   "/stripe",
+			headers: http.Header{
+				"X-Id": {"1"},
+			},
+			body:       `{"name":"it's"}`,
+			receivedAt: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "exported as base64",
+			script:  "printf '%s' 'eyJpZCI6MX0=' | base64 -d | curl -X POST http://localhost/hook --data-binary @-",
+			method:  "POST",
+			path:    "/hook",
+			headers: http.Header{},
+			body:    `{"id":1}`,
+		},
+		{
+			name:    "ansi-c quoting",
+			script:  `curl http://localhost/hook -d $'{"a":"\x41\u00e9\n"}'`,
+			method:  "POST",
+			path:    "/hook",
+			headers: http.Header{},
+			body:    "{\"a\":\"A\u00e9\n\"}",
+		},
+		{
+			name:    "double quotes",
+			script:  `curl "http://localhost/hook" -H "X-Name: \"quoted\" \$HOME" -XPATCH -d "{}"`,
+			method:  "PATCH",
+			path:    "/hook",
+			headers: http.Header{"X-Name": {`"quoted" $HOME`}},
+			body:    "{}",
+		},
+		{
+			name:    "repeated data",
+			script:  "curl --url=http://localhost/form -d a=1 --data b=2",
+			method:  "POST",
+			path:    "/form",
+			headers: http.Header{},
+			body:    "a=1&b=2",
+		},
+		{
+			name:   "user and json",
+			script: "curl -u user:pass --json '{}' -o /dev/null -A agent http://localhost/",
+			method: "POST",
+			path:   "/",
+			headers: http.Header{
+				"Authorization": {"Basic dXNlcjpwYXNz"},
+				"Content-Type":  {"application/json"},
+				"User-Agent":    {"agent"},
+			},
+			body: "{}",
+		},
+		{
+			name:    "get",
+			script:  "curl -sS http://localhost/health",
+			method:  "GET",
+			path:    "/health",
+			headers: http.Header{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := parseCurl([]byte(tt.script))
+			if err != nil {
+				t.Fatalf("parseCurl: %v", err)
+			}
+			if len(events) != 1 {
+				t.Fatalf("parseCurl returned %d events, want 1", len(events))
+			}
+
+			event := events[0]
+			if event.Method != tt.method {
+				t.Errorf("Method = %q, want %q", event.Method, tt.method)
+			}
+			if event.Path != tt.path {
+				t.Errorf("Path = %q, want %q", event.Path, tt.path)
+			}
+			if event.Query != tt.query {
+				t.Errorf("Query = %q, want %q", event.Query, tt.query)
+			}
+			if string(event.Body) != tt.body {
+				t.Errorf("Body = %q, want %q", event.Body, tt.body)
+			}
+			if !event.ReceivedAt.Equal(tt.receivedAt) {
+				t.Errorf("ReceivedAt = %v, want %v", event.ReceivedAt, tt.receivedAt)
+			}
+			if len(event.Headers) != len(tt.headers) {
+				t.Errorf("Headers = %v, want %v", event.Headers, tt.headers)
+			}
+			for name := range tt.headers {
+				if got, want := event.Headers.Get(name), tt.headers.Get(name); got != want {
+					t.Errorf("header %s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCurlSeveralCommands(t *testing.T) {
+	script := "curl http://localhost/a && curl http://localhost/b; echo done\ncurl http://localhost/c"
+
+	events, err := parseCurl([]byte(script))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for _, event := range events {
+		paths = append(paths, event.Path)
+	}
+	if len(paths) != 3 || paths[0] != "/a" || paths[1] != "/b" || paths[2] != "/c" {
+		t.Errorf("paths = %v, want [/a /b /c]", paths)
+	}
+}
+
+func TestParseCurlErrors(t *testing.T) {
+	tests := map[string]string{
+		"no commands":           "echo hello",
+		"no url":                "curl -sS -X POST",
+		"missing argument":      "curl http://localhost -H",
+		"invalid header":        "curl http://localhost -H 'no colon'",
+		"unterminated single":   "curl 'http://localhost",
+		"unterminated double":   `curl "http://localhost`,
+		"unterminated ansi-c":   "curl $'http://localhost",
+		"stdin without a pipe":  "curl http://localhost --data-binary @-",
+		"unsupported pipe into": "cat body.json | curl http://localhost -d @-",
+	}
+
+	for name, script := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseCurl([]byte(script)); err == nil {
+				t.Errorf("parseCurl(%q) succeeded", script)
+			}
+		})
+	}
+}
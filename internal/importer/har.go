@@ -0,0 +1,108 @@
+package importer
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lukeberry99/whook/internal/storage"
+)
+
+type harFile struct {
+	Log struct {
+		Entries []struct {
+			StartedDateTime time.Time `json:"startedDateTime"`
+			Request         struct {
+				Method   string         `json:"method"`
+				URL      string         `json:"url"`
+				Headers  []harNameValue `json:"headers"`
+				PostData *struct {
+					Text     string `json:"text"`
+					Encoding string `json:"encoding"`
+				} `json:"postData"`
+			} `json:"request"`
+			Response struct {
+				Status  int            `json:"status"`
+				Headers []harNameValue `json:"headers"`
+				Content struct {
+					Text     string `json:"text"`
+					Encoding string `json:"encoding"`
+				} `json:"content"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func parseHAR(data []byte) ([]Event, error) {
+	var har harFile
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, fmt.Errorf("decoding har: %w", err)
+	}
+
+	events := make([]Event, 0, len(har.Log.Entries))
+	for i, entry := range har.Log.Entries {
+		path, query, err := splitURL(entry.Request.URL)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i+1, err)
+		}
+
+		event := Event{
+			ReceivedAt: entry.StartedDateTime,
+			Method:     entry.Request.Method,
+			Path:       path,
+			Query:      query,
+			Headers:    harHeaders(entry.Request.Headers),
+		}
+
+		if postData := entry.Request.PostData; postData != nil {
+			event.Body, err = harText(postData.Text, postData.Encoding)
+			if err != nil {
+				return nil, fmt.Errorf("entry %d request body: %w", i+1, err)
+			}
+		}
+
+		// Entries for requests that never got a response have a zero status
+		if entry.Response.Status != 0 {
+			body, err := harText(entry.Response.Content.Text, entry.Response.Content.Encoding)
+			if err != nil {
+				return nil, fmt.Errorf("entry %d response body: %w", i+1, err)
+			}
+			event.Response = &storage.StoredResponse{
+				Status:  entry.Response.Status,
+				Headers: harHeaders(entry.Response.Headers),
+				Body:    string(body),
+			}
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+func harHeaders(list []harNameValue) http.Header {
+	headers := http.Header{}
+	for _, header := range list {
+		// HTTP/2 pseudo headers such as :authority aren't real headers
+		if strings.HasPrefix(header.Name, ":") {
+			continue
+		}
+		headers.Add(header.Name, header.Value)
+	}
+	return headers
+}
+
+func harText(text, encoding string) ([]byte, error) {
+	if encoding == "base64" {
+		return base64.StdEncoding.DecodeString(text)
+	}
+	return []byte(text), nil
+}
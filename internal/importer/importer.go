@@ -0,0 +1,185 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lukeberry99/whook/internal/storage"
+)
+
+const (
+	FormatHAR       = "har"
+	FormatJSONL     = "jsonl"
+	FormatCurl      = "curl"
+	FormatChargebee = "chargebee"
+)
+
+// ImportedTag is added to every imported event so they can be selected with
+// tag:imported
+const ImportedTag = "imported"
+
+var parsers = map[string]func(data []byte) ([]Event, error){
+	FormatHAR:       parseHAR,
+	FormatJSONL:     parseJSONL,
+	FormatCurl:      parseCurl,
+	FormatChargebee: parseChargebee,
+}
+
+// Event is a request read from an import source. Service and ReceivedAt are
+// only set when the source records them.
+type Event struct {
+	Service    string
+	ReceivedAt time.Time
+	Method     string
+	Path       string
+	Query      string
+	Headers    http.Header
+	Body       []byte
+	Response   *storage.StoredResponse
+}
+
+type Options struct {
+	Format string
+	// Source names where the events came from, usually the imported file
+	Source string
+	// Service is used for events whose source doesn't record one
+	Service string
+	// Tags are added to every event alongside ImportedTag
+	Tags []string
+}
+
+// Result lists the files events were stored in and the events that were
+// skipped, such as ones without a JSON body
+type Result struct {
+	Stored  []string
+	Skipped []string
+}
+
+// Formats returns the supported import formats
+func Formats() []string {
+	formats := make([]string, 0, len(parsers))
+	for format := range parsers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Detect guesses the format of data from its contents
+func Detect(data []byte) (string, error) {
+	trimmed := bytes.TrimSpace(data)
+
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		var probe struct {
+			Log  json.RawMessage `json:"log"`
+			List json.RawMessage `json:"list"`
+		}
+		// A single JSON document is either HAR or an events list, anything
+		// that fails to decode as one is assumed to be JSON lines
+		if err := json.Unmarshal(trimmed, &probe); err == nil {
+			switch {
+			case probe.Log != nil:
+				return FormatHAR, nil
+			case probe.List != nil:
+				return FormatChargebee, nil
+			}
+		}
+		return FormatJSONL, nil
+	}
+
+	if bytes.Contains(trimmed, []byte("curl ")) {
+		return FormatCurl, nil
+	}
+
+	return "", fmt.Errorf("unable to detect the format, pass one of: %s", strings.Join(Formats(), ", "))
+}
+
+// Parse reads the events in data
+func Parse(format string, data []byte) ([]Event, error) {
+	parse, ok := parsers[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown import format %q (available: %s)", format, strings.Join(Formats(), ", "))
+	}
+
+	return parse(data)
+}
+
+// Store writes events into store, keeping their original timestamps and
+// headers, and tags them as imported
+func Store(store *storage.FileStorage, events []Event, opts Options) (Result, error) {
+	var result Result
+	importedAt := time.Now()
+
+	for i, event := range events {
+		var payload interface{}
+		if err := json.Unmarshal(event.Body, &payload); err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("event %d: body isn't JSON", i+1))
+			continue
+		}
+
+		service := event.Service
+		if service == "" {
+			service = opts.Service
+		}
+
+		receivedAt := event.ReceivedAt
+		if receivedAt.IsZero() {
+			receivedAt = importedAt
+		}
+
+		filename, err := store.StoreAs(service, &storage.WebhookEvent{
+			ReceivedAt: receivedAt,
+			RawEvent:   payload,
+			Method:     event.Method,
+			Path:       event.Path,
+			Query:      event.Query,
+			Headers:    event.Headers,
+			Response:   event.Response,
+			Imported: &storage.ImportInfo{
+				Format:     opts.Format,
+				Source:     opts.Source,
+				ImportedAt: importedAt,
+			},
+		}, event.Body)
+		if err != nil {
+			return result, fmt.Errorf("storing event %d: %w", i+1, err)
+		}
+
+		name := filepath.Base(filename)
+		existing, err := store.Tags(name)
+		if err != nil {
+			return result, err
+		}
+		tags := append(append(existing, ImportedTag), opts.Tags...)
+		if err := store.SetTags(name, tags); err != nil {
+			return result, fmt.Errorf("tagging event %d: %w", i+1, err)
+		}
+
+		result.Stored = append(result.Stored, filename)
+	}
+
+	return result, nil
+}
+
+// splitURL returns the path and raw query of a captured URL, which may be
+// absolute or just a path
+func splitURL(rawURL string) (string, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", fmt.Errorf("parsing url %q: %w", rawURL, err)
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	return path, u.RawQuery, nil
+}
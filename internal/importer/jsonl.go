@@ -0,0 +1,73 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/lukeberry99/whook/internal/storage"
+)
+
+// jsonlRecord matches the lines written by whook export --format jsonl. Lines
+// without a body or raw_body are imported as the payload itself.
+type jsonlRecord struct {
+	Service    string                  `json:"service"`
+	ReceivedAt time.Time               `json:"received_at"`
+	Method     string                  `json:"method"`
+	Path       string                  `json:"path"`
+	Query      string                  `json:"query"`
+	Headers    http.Header             `json:"headers"`
+	Body       json.RawMessage         `json:"body"`
+	RawBody    []byte                  `json:"raw_body"`
+	Response   *storage.StoredResponse `json:"response"`
+}
+
+func parseJSONL(data []byte) ([]Event, error) {
+	var events []Event
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		var record jsonlRecord
+		if err := json.Unmarshal(text, &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if record.Body == nil && record.RawBody == nil {
+			events = append(events, Event{Body: append([]byte(nil), text...)})
+			continue
+		}
+
+		body := record.RawBody
+		if len(body) == 0 {
+			body = record.Body
+		}
+
+		events = append(events, Event{
+			Service:    record.Service,
+			ReceivedAt: record.ReceivedAt,
+			Method:     record.Method,
+			Path:       record.Path,
+			Query:      record.Query,
+			Headers:    record.Headers,
+			Body:       body,
+			Response:   record.Response,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading lines: %w", err)
+	}
+
+	return events, nil
+}
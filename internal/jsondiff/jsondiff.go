@@ -0,0 +1,166 @@
+// Package jsondiff compares JSON documents decoded with jsonpath.Decode path
+// by path.
+package jsondiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lukeberry99/whook/internal/jsonpath"
+)
+
+// Kind is how a path differs between two documents
+type Kind int
+
+const (
+	Added Kind = iota
+	Removed
+	Changed
+)
+
+// Change is a path that differs, Old is unset when it was added and New when
+// it was removed
+type Change struct {
+	Path string
+	Kind Kind
+	Old  interface{}
+	New  interface{}
+}
+
+// Compare returns the paths that differ between a and b, ordered by path.
+// Ignore holds key names, which are skipped wherever they appear, and $ JSON
+// paths, which skip the value at that path.
+func Compare(a, b interface{}, ignore []string) []Change {
+	c := comparer{keys: map[string]bool{}, paths: map[string]bool{}}
+	for _, name := range ignore {
+		if jsonpath.IsPath(name) {
+			c.paths[name] = true
+		} else {
+			c.keys[name] = true
+		}
+	}
+
+	c.compare("$", a, b)
+	return c.changes
+}
+
+type comparer struct {
+	keys    map[string]bool
+	paths   map[string]bool
+	changes []Change
+}
+
+func (c *comparer) compare(path string, a, b interface{}) {
+	if c.paths[path] {
+		return
+	}
+
+	switch a := a.(type) {
+	case map[string]interface{}:
+		if b, ok := b.(map[string]interface{}); ok {
+			c.compareObjects(path, a, b)
+			return
+		}
+	case []interface{}:
+		if b, ok := b.([]interface{}); ok {
+			c.compareArrays(path, a, b)
+			return
+		}
+	default:
+		if equal(a, b) {
+			return
+		}
+	}
+
+	c.changes = append(c.changes, Change{Path: path, Kind: Changed, Old: a, New: b})
+}
+
+func (c *comparer) compareObjects(path string, a, b map[string]interface{}) {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if c.keys[key] {
+			continue
+		}
+
+		keyPath := path + member(key)
+		oldValue, inA := a[key]
+		newValue, inB := b[key]
+		switch {
+		case !inA:
+			if !c.paths[keyPath] {
+				c.changes = append(c.changes, Change{Path: keyPath, Kind: Added, New: newValue})
+			}
+		case !inB:
+			if !c.paths[keyPath] {
+				c.changes = append(c.changes, Change{Path: keyPath, Kind: Removed, Old: oldValue})
+			}
+		default:
+			c.compare(keyPath, oldValue, newValue)
+		}
+	}
+}
+
+func (c *comparer) compareArrays(path string, a, b []interface{}) {
+	for i := 0; i < max(len(a), len(b)); i++ {
+		indexPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= len(a):
+			if !c.paths[indexPath] {
+				c.changes = append(c.changes, Change{Path: indexPath, Kind: Added, New: b[i]})
+			}
+		case i >= len(b):
+			if !c.paths[indexPath] {
+				c.changes = append(c.changes, Change{Path: indexPath, Kind: Removed, Old: a[i]})
+			}
+		default:
+			c.compare(indexPath, a[i], b[i])
+		}
+	}
+}
+
+// member is the path step for key, bracketed when it isn't a plain name
+func member(key string) string {
+	if key == "" || strings.ContainsAny(key, ".[]'\" ") {
+		return "['" + key + "']"
+	}
+	return "." + key
+}
+
+func equal(a, b interface{}) bool {
+	switch a := a.(type) {
+	case nil:
+		return b == nil
+	case json.Number:
+		b, ok := b.(json.Number)
+		return ok && a == b
+	case string:
+		b, ok := b.(string)
+		return ok && a == b
+	case bool:
+		b, ok := b.(bool)
+		return ok && a == b
+	}
+	return false
+}
+
+// Format renders a value as compact JSON, so strings and numbers can be told
+// apart
+func Format(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
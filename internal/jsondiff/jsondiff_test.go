@@ -0,0 +1,123 @@
+package jsondiff
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lukeberry99/whook/internal/jsonpath"
+)
+
+func decode(t *testing.T, data string) interface{} {
+	t.Helper()
+	value, err := jsonpath.Decode([]byte(data))
+	if err != nil {
+		t.Fatalf("decoding %s: %v", data, err)
+	}
+	return value
+}
+
+type change struct {
+	path     string
+	kind     Kind
+	old, new string
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name   string
+		a, b   string
+		ignore []string
+		want   []change
+	}{
+		{
+			name: "equal",
+			a:    `{"id":1,"tags":["a","b"],"ok":true,"x":null}`,
+			b:    `{"x":null,"ok":true,"tags":["a","b"],"id":1}`,
+		},
+		{
+			name: "changed added and removed",
+			a:    `{"id":1,"status":"open","old":true}`,
+			b:    `{"id":2,"status":"open","new":false}`,
+			want: []change{
+				{"$.id", Changed, "1", "2"},
+				{"$.new", Added, "null", "false"},
+				{"$.old", Removed, "true", "null"},
+			},
+		},
+		{
+			name: "nested and arrays",
+			a:    `{"data":{"items":[{"n":1},{"n":2}]}}`,
+			b:    `{"data":{"items":[{"n":1},{"n":3},{"n":4}]}}`,
+			want: []change{
+				{"$.data.items[1].n", Changed, "2", "3"},
+				{"$.data.items[2]", Added, "null", `{"n":4}`},
+			},
+		},
+		{
+			name: "type changes",
+			a:    `{"a":1,"b":{"c":1},"d":[1]}`,
+			b:    `{"a":"1","b":[1],"d":null}`,
+			want: []change{
+				{"$.a", Changed, "1", `"1"`},
+				{"$.b", Changed, `{"c":1}`, "[1]"},
+				{"$.d", Changed, "[1]", "null"},
+			},
+		},
+		{
+			name: "keys needing brackets",
+			a:    `{"a.b":1,"":1}`,
+			b:    `{"a.b":2,"":2}`,
+			want: []change{
+				{"$['']", Changed, "1", "2"},
+				{"$['a.b']", Changed, "1", "2"},
+			},
+		},
+		{
+			name:   "ignored keys anywhere",
+			a:      `{"id":1,"created_at":1,"data":{"created_at":1,"n":1}}`,
+			b:      `{"id":1,"created_at":2,"data":{"created_at":2,"n":2}}`,
+			ignore: []string{"created_at"},
+			want: []change{
+				{"$.data.n", Changed, "1", "2"},
+			},
+		},
+		{
+			name:   "ignored paths",
+			a:      `{"id":1,"data":{"id":1,"items":[1,2]}}`,
+			b:      `{"id":2,"data":{"id":2,"items":[1,2,3]},"extra":1}`,
+			ignore: []string{"$.id", "$.data.items[2]", "$.extra"},
+			want: []change{
+				{"$.data.id", Changed, "1", "2"},
+			},
+		},
+		{
+			name:   "ignored root",
+			a:      `{"id":1}`,
+			b:      `{"id":2}`,
+			ignore: []string{"$"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := Compare(decode(t, tt.a), decode(t, tt.b), tt.ignore)
+
+			var got []change
+			for _, c := range changes {
+				got = append(got, change{c.Path, c.Kind, Format(c.Old), Format(c.New)})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Compare = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareKeepsNumberPrecision(t *testing.T) {
+	a := decode(t, `{"amount":9007199254740993}`)
+	b := decode(t, `{"amount":9007199254740992}`)
+
+	if changes := Compare(a, b, nil); len(changes) != 1 {
+		t.Errorf("Compare of numbers past float64 precision = %v, want one change", changes)
+	}
+}
@@ -0,0 +1,144 @@
+package jsonpath
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Path is a parsed JSON path such as $.content.invoice.line_items[0].amount.
+// Only member and index access are supported, there are no wildcards or
+// filters.
+type Path struct {
+	expr  string
+	steps []step
+}
+
+type step struct {
+	key   string
+	index int
+	isKey bool
+}
+
+// IsPath reports whether expr looks like a JSON path rather than a name
+func IsPath(expr string) bool {
+	return strings.HasPrefix(expr, "$")
+}
+
+// Parse reads a path made of .key, ['key'] and [index] steps after the $
+func Parse(expr string) (Path, error) {
+	if !IsPath(expr) {
+		return Path{}, fmt.Errorf("json path %q must start with $", expr)
+	}
+
+	p := Path{expr: expr}
+	rest := expr[1:]
+
+	for rest != "" {
+		switch {
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : 1+end]
+			if key == "" {
+				return Path{}, fmt.Errorf("json path %q has an empty key", expr)
+			}
+			p.steps = append(p.steps, step{key: key, isKey: true})
+			rest = rest[1+end:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return Path{}, fmt.Errorf("json path %q has an unclosed [", expr)
+			}
+			inner := rest[1:end]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				p.steps = append(p.steps, step{key: inner[1 : len(inner)-1], isKey: true})
+			} else {
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return Path{}, fmt.Errorf("json path %q has an invalid index %q", expr, inner)
+				}
+				p.steps = append(p.steps, step{index: index})
+			}
+			rest = rest[end+1:]
+		default:
+			return Path{}, fmt.Errorf("json path %q: unexpected %q", expr, rest[0])
+		}
+	}
+
+	return p, nil
+}
+
+func (p Path) String() string {
+	return p.expr
+}
+
+// Get looks the path up in a document decoded with Decode. Negative indexes
+// count from the end of an array.
+func (p Path) Get(doc interface{}) (interface{}, bool) {
+	current := doc
+	for _, s := range p.steps {
+		if s.isKey {
+			object, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			current, ok = object[s.key]
+			if !ok {
+				return nil, false
+			}
+			continue
+		}
+
+		array, ok := current.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		index := s.index
+		if index < 0 {
+			index += len(array)
+		}
+		if index < 0 || index >= len(array) {
+			return nil, false
+		}
+		current = array[index]
+	}
+	return current, true
+}
+
+// Decode decodes a JSON document keeping numbers exactly as they were sent
+func Decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Format renders a value for display, strings without quotes and objects and
+// arrays as compact JSON
+func Format(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
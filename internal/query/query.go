@@ -0,0 +1,211 @@
+package query
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lukeberry99/whook/internal/config"
+	"github.com/lukeberry99/whook/internal/eventtype"
+	"github.com/lukeberry99/whook/internal/storage"
+)
+
+// Query selects stored events. Every populated field must match, and within
+// a field any one of the values may match.
+type Query struct {
+	Services   []string
+	EventTypes []string
+	Tags       []string
+	Since      time.Time
+	Until      time.Time
+	// Text terms must all appear somewhere in the payload
+	Text []string
+}
+
+type Match struct {
+	Item      storage.EventListItem
+	Event     *storage.StoredEvent
+	EventType string
+	Tags      []string
+}
+
+// Parse reads a query such as
+//
+//	service:chargebee type:payment_succeeded tag:incident since:2h cust_123
+//
+// since and until accept RFC3339 times, dates or durations before now
+func Parse(expr string) (Query, error) {
+	var q Query
+
+	for _, term := range strings.Fields(expr) {
+		key, value, ok := strings.Cut(term, ":")
+		if !ok || value == "" {
+			q.Text = append(q.Text, term)
+			continue
+		}
+
+		switch key {
+		case "service":
+			q.Services = append(q.Services, value)
+		case "type":
+			q.EventTypes = append(q.EventTypes, value)
+		case "tag":
+			q.Tags = append(q.Tags, value)
+		case "since":
+			t, err := parseTime(value)
+			if err != nil {
+				return Query{}, fmt.Errorf("invalid since: %w", err)
+			}
+			q.Since = t
+		case "until":
+			t, err := parseTime(value)
+			if err != nil {
+				return Query{}, fmt.Errorf("invalid until: %w", err)
+			}
+			q.Until = t
+		default:
+			q.Text = append(q.Text, term)
+		}
+	}
+
+	return q, nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a duration, date or RFC3339 time", value)
+}
+
+func (q Query) IsEmpty() bool {
+	return len(q.Services) == 0 && len(q.EventTypes) == 0 && len(q.Tags) == 0 &&
+		q.Since.IsZero() && q.Until.IsZero() && len(q.Text) == 0
+}
+
+// Run returns the events across every service that match q, newest first
+func Run(store *storage.FileStorage, cfg *config.Config, q Query) ([]Match, error) {
+	items, err := store.ListAll()
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := store.AllTags()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []Match
+	for _, item := range items {
+		if len(q.Services) > 0 && !contains(q.Services, item.ServiceName) {
+			continue
+		}
+		if !q.Since.IsZero() && item.Time.Before(q.Since) {
+			continue
+		}
+		if !q.Until.IsZero() && item.Time.After(q.Until) {
+			continue
+		}
+		if len(q.Tags) > 0 && !containsAny(tags[item.Filename], q.Tags) {
+			continue
+		}
+
+		event, err := storage.LoadEvent(item.Path)
+		if err != nil {
+			continue
+		}
+
+		body := event.Body()
+		eventType := eventtype.Extract(cfg.Services[item.ServiceName], event.Headers, body)
+		if len(q.EventTypes) > 0 && !contains(q.EventTypes, eventType) {
+			continue
+		}
+
+		if !containsText(body, q.Text) {
+			continue
+		}
+
+		matches = append(matches, Match{
+			Item:      item,
+			Event:     event,
+			EventType: eventType,
+			Tags:      tags[item.Filename],
+		})
+	}
+
+	// Filenames only start with the time of day, so events from different
+	// days are ordered by when they were received
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].receivedAt().After(matches[j].receivedAt())
+	})
+
+	return matches, nil
+}
+
+// receivedAt falls back to the listed time for events stored without one
+func (m Match) receivedAt() time.Time {
+	if m.Event.ReceivedAt.IsZero() {
+		return m.Item.Time
+	}
+	return m.Event.ReceivedAt
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(values []string, wanted []string) bool {
+	for _, w := range wanted {
+		if contains(values, w) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsText(body []byte, terms []string) bool {
+	for _, term := range terms {
+		if !bytes.Contains(body, []byte(term)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Load returns every item as a match without filtering, events that can't be
+// read are skipped
+func Load(store *storage.FileStorage, cfg *config.Config, items []storage.EventListItem) ([]Match, error) {
+	tags, err := store.AllTags()
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]Match, 0, len(items))
+	for _, item := range items {
+		event, err := storage.LoadEvent(item.Path)
+		if err != nil {
+			continue
+		}
+
+		matches = append(matches, Match{
+			Item:      item,
+			Event:     event,
+			EventType: eventtype.Extract(cfg.Services[item.ServiceName], event.Headers, event.Body()),
+			Tags:      tags[item.Filename],
+		})
+	}
+
+	return matches, nil
+}
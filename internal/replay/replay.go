@@ -0,0 +1,139 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/lukeberry99/whook/internal/config"
+	"github.com/lukeberry99/whook/internal/signing"
+	"github.com/lukeberry99/whook/internal/transform"
+)
+
+type Request struct {
+	Target  string
+	Method  string
+	Headers http.Header
+	Body    []byte
+	Signer  signing.Signer
+	// Transform is applied before signing so the signature covers the
+	// payload that's actually sent
+	Transform *transform.Pipeline
+}
+
+type Result struct {
+	StatusCode int
+	Headers    http.Header
+	Body       []byte
+	Duration   time.Duration
+}
+
+func Send(ctx context.Context, client *http.Client, r Request) (*Result, error) {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	method := r.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	msg, err := r.Transform.Apply(transform.Message{
+		URL:     r.Target,
+		Method:  method,
+		Headers: r.Headers,
+		Body:    r.Body,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, msg.Method, msg.URL, bytes.NewReader(msg.Body))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	for key, values := range msg.Headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Always sign with a fresh timestamp so edited payloads still verify
+	if r.Signer != nil {
+		if err := r.Signer.Sign(req, msg.Body, time.Now()); err != nil {
+			return nil, fmt.Errorf("signing request: %w", err)
+		}
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+
+	return &Result{
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header,
+		Body:       body,
+		Duration:   time.Since(start),
+	}, nil
+}
+
+// ForService returns a request that will be signed and transformed the way
+// the service is configured, callers fill in the target and body
+func ForService(service config.ServiceConfig) (Request, error) {
+	signer, err := SignerFor(service)
+	if err != nil {
+		return Request{}, fmt.Errorf("configuring signing: %w", err)
+	}
+
+	pipeline, err := transform.New(service.Transforms)
+	if err != nil {
+		return Request{}, fmt.Errorf("configuring transforms: %w", err)
+	}
+
+	return Request{Signer: signer, Transform: pipeline}, nil
+}
+
+func SignerFor(service config.ServiceConfig) (signing.Signer, error) {
+	return signing.New(signing.Config{
+		Scheme:   signing.Scheme(service.Signing.Scheme),
+		Secret:   service.Signing.Secret,
+		Username: service.Signing.Username,
+		Password: service.Signing.Password,
+		Header:   service.Signing.Header,
+		Prefix:   service.Signing.Prefix,
+	})
+}
+
+// Verify checks the signature of a received event against the service's
+// signing configuration, signing.ErrNotSigned is returned when it has none
+func Verify(service config.ServiceConfig, header http.Header, body []byte) error {
+	signer, err := SignerFor(service)
+	if err != nil {
+		return fmt.Errorf("configuring signing: %w", err)
+	}
+
+	verifier, ok := signer.(signing.Verifier)
+	if !ok {
+		return fmt.Errorf("%s signatures can't be verified", service.Signing.Scheme)
+	}
+	if signing.IsRedacted(header) {
+		return signing.ErrRedacted
+	}
+
+	return verifier.Verify(header, body)
+}
@@ -0,0 +1,204 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lukeberry99/whook/internal/config"
+	"github.com/lukeberry99/whook/internal/generate"
+	"github.com/lukeberry99/whook/internal/replay"
+	"github.com/lukeberry99/whook/internal/storage"
+	"github.com/robfig/cron/v3"
+)
+
+type JobState struct {
+	Name       string
+	Service    string
+	Schedule   string
+	Runs       int
+	LastRun    time.Time
+	NextRun    time.Time
+	LastResult string
+}
+
+type job struct {
+	cfg     config.ScheduleConfig
+	entryID cron.EntryID
+	state   JobState
+}
+
+// Scheduler fires synthetic or captured events on the configured schedules
+type Scheduler struct {
+	cfg     *config.Config
+	store   *storage.FileStorage
+	logChan chan<- string
+	cron    *cron.Cron
+	// done is closed by Stop, so jobs still running stop waiting to log
+	done chan struct{}
+
+	mu     sync.Mutex
+	jobs   []*job
+	paused bool
+}
+
+func New(cfg *config.Config, store *storage.FileStorage, logChan chan<- string) (*Scheduler, error) {
+	s := &Scheduler{
+		cfg:     cfg,
+		store:   store,
+		logChan: logChan,
+		cron:    cron.New(),
+		done:    make(chan struct{}),
+	}
+
+	for i, scheduleCfg := range cfg.Schedules {
+		if scheduleCfg.Name == "" {
+			scheduleCfg.Name = fmt.Sprintf("schedule %d", i+1)
+		}
+		if (scheduleCfg.Template == "") == (scheduleCfg.Event == "") {
+			return nil, fmt.Errorf("%s: exactly one of template or event must be set", scheduleCfg.Name)
+		}
+		if scheduleCfg.Every > 0 && scheduleCfg.Cron != "" {
+			return nil, fmt.Errorf("%s: only one of every or cron can be set", scheduleCfg.Name)
+		}
+
+		spec := scheduleCfg.Cron
+		if scheduleCfg.Every > 0 {
+			spec = "@every " + scheduleCfg.Every.String()
+		}
+		if spec == "" {
+			return nil, fmt.Errorf("%s: one of every or cron must be set", scheduleCfg.Name)
+		}
+
+		j := &job{
+			cfg: scheduleCfg,
+			state: JobState{
+				Name:     scheduleCfg.Name,
+				Service:  scheduleCfg.Service,
+				Schedule: spec,
+			},
+		}
+
+		id, err := s.cron.AddFunc(spec, func() { s.fire(j) })
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid schedule %q: %w", scheduleCfg.Name, spec, err)
+		}
+		j.entryID = id
+		s.jobs = append(s.jobs, j)
+	}
+
+	return s, nil
+}
+
+func (s *Scheduler) Start() {
+	s.cron.Start()
+}
+
+// Stop waits for any running jobs to finish, nothing is logged once it
+// returns so the log channel can be closed
+func (s *Scheduler) Stop() {
+	ctx := s.cron.Stop()
+	close(s.done)
+	<-ctx.Done()
+}
+
+func (s *Scheduler) Pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused = true
+}
+
+func (s *Scheduler) Resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused = false
+}
+
+func (s *Scheduler) Paused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
+func (s *Scheduler) Jobs() []JobState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states := make([]JobState, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		state := j.state
+		state.NextRun = s.cron.Entry(j.entryID).Next
+		states = append(states, state)
+	}
+	return states
+}
+
+func (s *Scheduler) fire(j *job) {
+	// Paused schedules keep ticking but skip their events, so resuming
+	// doesn't cause a burst of catch up events
+	if s.Paused() {
+		return
+	}
+
+	result := s.run(j.cfg)
+	select {
+	case s.logChan <- fmt.Sprintf("Schedule %s (%s): %s", j.cfg.Name, j.cfg.Service, result):
+	case <-s.done:
+	}
+
+	s.mu.Lock()
+	j.state.Runs++
+	j.state.LastRun = time.Now()
+	j.state.LastResult = result
+	s.mu.Unlock()
+}
+
+func (s *Scheduler) run(cfg config.ScheduleConfig) string {
+	body, err := s.body(cfg)
+	if err != nil {
+		return fmt.Sprintf("error: %v", err)
+	}
+
+	if cfg.Target == "" {
+		filename, err := s.store.StoreBody(cfg.Service, time.Now(), body)
+		if err != nil {
+			return fmt.Sprintf("error storing event: %v", err)
+		}
+		return fmt.Sprintf("stored %s", filename)
+	}
+
+	req, err := replay.ForService(s.cfg.Services[cfg.Service])
+	if err != nil {
+		return fmt.Sprintf("error: %v", err)
+	}
+	req.Target = s.cfg.ResolveTarget(cfg.Target)
+	req.Body = body
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	response, err := replay.Send(ctx, nil, req)
+	if err != nil {
+		return fmt.Sprintf("error: %v", err)
+	}
+
+	return fmt.Sprintf("sent to %s, got %d", req.Target, response.StatusCode)
+}
+
+func (s *Scheduler) body(cfg config.ScheduleConfig) ([]byte, error) {
+	if cfg.Event != "" {
+		data, err := os.ReadFile(cfg.Event)
+		if err != nil {
+			return nil, fmt.Errorf("reading event file: %w", err)
+		}
+		return storage.ExtractEventBody(data)
+	}
+
+	tmpl, err := generate.Find(cfg.Service, generate.Dir(cfg.Service, s.cfg.Services[cfg.Service]), cfg.Template)
+	if err != nil {
+		return nil, err
+	}
+	return tmpl.Render(cfg.Vars)
+}
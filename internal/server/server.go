@@ -6,14 +6,17 @@ import (
 
 	"github.com/lukeberry99/whook/internal/config"
 	"github.com/lukeberry99/whook/internal/handler"
+	"github.com/lukeberry99/whook/internal/hooks"
 	"github.com/lukeberry99/whook/internal/storage"
 )
 
-func NewWebhookServer(cfg *config.Config, store *storage.FileStorage, logChan chan<- string) *http.Server {
+// NewWebhookServer returns the server storing incoming webhooks, which
+// dispatches their hooks to runner
+func NewWebhookServer(cfg *config.Config, store *storage.FileStorage, runner *hooks.Runner, logChan chan<- string) *http.Server {
 	return &http.Server{
 		Addr: fmt.Sprintf(":%d", cfg.Server.Port),
 		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			handler.WebhookHandler(w, r, store, logChan)
+			handler.WebhookHandler(w, r, cfg, store, runner, logChan)
 		}),
 	}
 }
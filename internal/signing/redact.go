@@ -0,0 +1,74 @@
+package signing
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Redacted replaces header values that are masked in shared copies of events
+const Redacted = "REDACTED"
+
+// ErrRedacted is returned when verifying an event whose signature or
+// credentials were redacted before it was shared
+var ErrRedacted = errors.New("signature was redacted when the event was shared")
+
+// credentialHeaders authenticate the sender on their own, so they're always
+// masked
+var credentialHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "X-Api-Key"}
+
+// RedactHeaders returns a copy of header with credentials masked, and
+// signatures too unless keepSignatures is set. A signature only proves a
+// payload to someone holding the secret, but can be resent with it. extra
+// names more signature headers, such as a service's own HMAC header.
+func RedactHeaders(header http.Header, keepSignatures bool, extra ...string) http.Header {
+	redacted := header.Clone()
+	for name, values := range redacted {
+		if !isCredential(name) && (keepSignatures || !IsSignatureHeader(name, extra...)) {
+			continue
+		}
+		for i := range values {
+			values[i] = Redacted
+		}
+	}
+	return redacted
+}
+
+// IsRedacted reports whether any header was masked by RedactHeaders
+func IsRedacted(header http.Header) bool {
+	for _, values := range header {
+		for _, value := range values {
+			if value == Redacted {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isCredential(name string) bool {
+	for _, credential := range credentialHeaders {
+		if strings.EqualFold(name, credential) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSignatureHeader reports whether a header carries a signature, going by
+// its name or extra, the service's own signature header
+func IsSignatureHeader(name string, extra ...string) bool {
+	for _, header := range extra {
+		if header != "" && strings.EqualFold(name, header) {
+			return true
+		}
+	}
+
+	lower := strings.ToLower(name)
+	for _, part := range []string{"signature", "token", "secret", "hmac"} {
+		if strings.Contains(lower, part) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package signing
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRedactHeaders(t *testing.T) {
+	header := http.Header{
+		"Authorization":       {"Bearer abc"},
+		"Content-Type":        {"application/json"},
+		"X-Hub-Signature-256": {"sha256=abc"},
+		"X-Shop-Hmac":         {"abc"},
+		"X-Custom-Check":      {"abc"},
+	}
+
+	redacted := RedactHeaders(header, false, "X-Custom-Check")
+	for _, name := range []string{"Authorization", "X-Hub-Signature-256", "X-Shop-Hmac", "X-Custom-Check"} {
+		if got := redacted.Get(name); got != Redacted {
+			t.Errorf("%s = %q, want %q", name, got, Redacted)
+		}
+	}
+	if got := redacted.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want it unchanged", got)
+	}
+	if header.Get("Authorization") != "Bearer abc" {
+		t.Error("RedactHeaders changed the original header")
+	}
+
+	kept := RedactHeaders(header, true)
+	if got := kept.Get("X-Hub-Signature-256"); got != "sha256=abc" {
+		t.Errorf("X-Hub-Signature-256 = %q with keepSignatures, want it unchanged", got)
+	}
+	if got := kept.Get("Authorization"); got != Redacted {
+		t.Errorf("Authorization = %q with keepSignatures, want %q", got, Redacted)
+	}
+}
+
+func TestIsRedacted(t *testing.T) {
+	header := http.Header{"X-Hub-Signature-256": {"sha256=abc"}}
+	if IsRedacted(header) {
+		t.Error("IsRedacted of a signed header = true")
+	}
+	if !IsRedacted(RedactHeaders(header, false)) {
+		t.Error("IsRedacted of a redacted header = false")
+	}
+}
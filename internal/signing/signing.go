@@ -0,0 +1,180 @@
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Scheme string
+
+const (
+	SchemeNone             Scheme = ""
+	SchemeChargebee        Scheme = "chargebee"
+	SchemeStripe           Scheme = "stripe"
+	SchemeGitHub           Scheme = "github"
+	SchemeStandardWebhooks Scheme = "standard_webhooks"
+	SchemeHMAC             Scheme = "hmac"
+)
+
+type Config struct {
+	Scheme   Scheme
+	Secret   string
+	Username string
+	Password string
+	// Header and Prefix only apply to the generic HMAC scheme
+	Header string
+	Prefix string
+}
+
+type Signer interface {
+	Sign(req *http.Request, body []byte, now time.Time) error
+}
+
+func New(config Config) (Signer, error) {
+	switch config.Scheme {
+	case SchemeNone:
+		return noopSigner{}, nil
+	case SchemeChargebee:
+		if config.Username == "" {
+			return nil, fmt.Errorf("chargebee signing requires a username")
+		}
+		return chargebeeSigner{username: config.Username, password: config.Password}, nil
+	case SchemeStripe:
+		if config.Secret == "" {
+			return nil, fmt.Errorf("stripe signing requires a secret")
+		}
+		return stripeSigner{secret: config.Secret}, nil
+	case SchemeGitHub:
+		if config.Secret == "" {
+			return nil, fmt.Errorf("github signing requires a secret")
+		}
+		return githubSigner{secret: config.Secret}, nil
+	case SchemeStandardWebhooks:
+		key, err := decodeStandardWebhooksSecret(config.Secret)
+		if err != nil {
+			return nil, err
+		}
+		return standardWebhooksSigner{key: key}, nil
+	case SchemeHMAC:
+		if config.Secret == "" {
+			return nil, fmt.Errorf("hmac signing requires a secret")
+		}
+		header := config.Header
+		if header == "" {
+			header = "X-Signature"
+		}
+		return hmacSigner{secret: config.Secret, header: header, prefix: config.Prefix}, nil
+	default:
+		return nil, fmt.Errorf("unsupported signing scheme: %s", config.Scheme)
+	}
+}
+
+type noopSigner struct{}
+
+func (noopSigner) Sign(req *http.Request, body []byte, now time.Time) error {
+	return nil
+}
+
+// Chargebee doesn't sign payloads, it protects webhook endpoints with basic auth
+type chargebeeSigner struct {
+	username string
+	password string
+}
+
+func (s chargebeeSigner) Sign(req *http.Request, body []byte, now time.Time) error {
+	req.SetBasicAuth(s.username, s.password)
+	return nil
+}
+
+type stripeSigner struct {
+	secret string
+}
+
+func (s stripeSigner) Sign(req *http.Request, body []byte, now time.Time) error {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := hexHMAC([]byte(s.secret), []byte(timestamp+"."), body)
+	req.Header.Set("Stripe-Signature", fmt.Sprintf("t=%s,v1=%s", timestamp, signature))
+	return nil
+}
+
+type githubSigner struct {
+	secret string
+}
+
+func (s githubSigner) Sign(req *http.Request, body []byte, now time.Time) error {
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hexHMAC([]byte(s.secret), body))
+	return nil
+}
+
+type standardWebhooksSigner struct {
+	key []byte
+}
+
+func (s standardWebhooksSigner) Sign(req *http.Request, body []byte, now time.Time) error {
+	id := req.Header.Get("webhook-id")
+	if id == "" {
+		generated, err := generateMessageID()
+		if err != nil {
+			return err
+		}
+		id = generated
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(id + "." + timestamp + "."))
+	mac.Write(body)
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	req.Header.Set("webhook-id", id)
+	req.Header.Set("webhook-timestamp", timestamp)
+	req.Header.Set("webhook-signature", "v1,"+signature)
+	return nil
+}
+
+type hmacSigner struct {
+	secret string
+	header string
+	prefix string
+}
+
+func (s hmacSigner) Sign(req *http.Request, body []byte, now time.Time) error {
+	req.Header.Set(s.header, s.prefix+hexHMAC([]byte(s.secret), body))
+	return nil
+}
+
+func hexHMAC(key []byte, parts ...[]byte) string {
+	mac := hmac.New(sha256.New, key)
+	for _, part := range parts {
+		mac.Write(part)
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Standard Webhooks secrets are base64 encoded and usually carry a whsec_ prefix
+func decodeStandardWebhooksSecret(secret string) ([]byte, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("standard webhooks signing requires a secret")
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, "whsec_"))
+	if err != nil {
+		return nil, fmt.Errorf("decoding standard webhooks secret: %w", err)
+	}
+	return key, nil
+}
+
+func generateMessageID() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generating message id: %w", err)
+	}
+	return "msg_" + hex.EncodeToString(buf), nil
+}
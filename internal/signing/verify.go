@@ -0,0 +1,121 @@
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrNotSigned is returned when verifying against a service without a
+	// signing scheme
+	ErrNotSigned = errors.New("no signing scheme is configured")
+	// ErrMismatch is returned when a signature doesn't match the payload
+	ErrMismatch = errors.New("signature doesn't match")
+)
+
+// Verifier checks the signature of a received request. Timestamps are taken
+// from the request but not checked against a tolerance, so captured events
+// can be verified long after they were received.
+type Verifier interface {
+	Verify(header http.Header, body []byte) error
+}
+
+func (noopSigner) Verify(header http.Header, body []byte) error {
+	return ErrNotSigned
+}
+
+func (s chargebeeSigner) Verify(header http.Header, body []byte) error {
+	username, password, ok := (&http.Request{Header: header}).BasicAuth()
+	if !ok {
+		return fmt.Errorf("missing basic auth credentials")
+	}
+	if !equal(username, s.username) || !equal(password, s.password) {
+		return fmt.Errorf("basic auth credentials don't match")
+	}
+	return nil
+}
+
+func (s stripeSigner) Verify(header http.Header, body []byte) error {
+	value := header.Get("Stripe-Signature")
+	if value == "" {
+		return fmt.Errorf("missing Stripe-Signature header")
+	}
+
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(value, ",") {
+		key, val, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = val
+		case "v1":
+			signatures = append(signatures, val)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return fmt.Errorf("malformed Stripe-Signature header")
+	}
+
+	expected := hexHMAC([]byte(s.secret), []byte(timestamp+"."), body)
+	for _, signature := range signatures {
+		if equal(signature, expected) {
+			return nil
+		}
+	}
+	return ErrMismatch
+}
+
+func (s githubSigner) Verify(header http.Header, body []byte) error {
+	value := header.Get("X-Hub-Signature-256")
+	if value == "" {
+		return fmt.Errorf("missing X-Hub-Signature-256 header")
+	}
+	if !equal(value, "sha256="+hexHMAC([]byte(s.secret), body)) {
+		return ErrMismatch
+	}
+	return nil
+}
+
+func (s standardWebhooksSigner) Verify(header http.Header, body []byte) error {
+	id := header.Get("webhook-id")
+	timestamp := header.Get("webhook-timestamp")
+	value := header.Get("webhook-signature")
+	if id == "" || timestamp == "" || value == "" {
+		return fmt.Errorf("missing webhook-id, webhook-timestamp or webhook-signature header")
+	}
+
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(id + "." + timestamp + "."))
+	mac.Write(body)
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	// The header holds space separated signatures while secrets are rotated
+	for _, signature := range strings.Fields(value) {
+		version, sig, _ := strings.Cut(signature, ",")
+		if version == "v1" && equal(sig, expected) {
+			return nil
+		}
+	}
+	return ErrMismatch
+}
+
+func (s hmacSigner) Verify(header http.Header, body []byte) error {
+	value := header.Get(s.header)
+	if value == "" {
+		return fmt.Errorf("missing %s header", s.header)
+	}
+	if !equal(value, s.prefix+hexHMAC([]byte(s.secret), body)) {
+		return ErrMismatch
+	}
+	return nil
+}
+
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,168 @@
+package signing
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+var body = []byte(`{"id":"evt_1","type":"invoice.paid"}`)
+
+var schemeConfigs = []Config{
+	{Scheme: SchemeChargebee, Username: "whook", Password: "s3cret"},
+	{Scheme: SchemeStripe, Secret: "whsec_test"},
+	{Scheme: SchemeGitHub, Secret: "s3cret"},
+	{Scheme: SchemeStandardWebhooks, Secret: "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw"},
+	{Scheme: SchemeHMAC, Secret: "s3cret"},
+	{Scheme: SchemeHMAC, Secret: "s3cret", Header: "X-Shopify-Hmac", Prefix: "sha256="},
+}
+
+func sign(t *testing.T, config Config) (Signer, http.Header) {
+	t.Helper()
+
+	signer, err := New(config)
+	if err != nil {
+		t.Fatalf("New(%s): %v", config.Scheme, err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "http://localhost/webhooks", strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := signer.Sign(req, body, time.Unix(1700000000, 0)); err != nil {
+		t.Fatalf("Sign(%s): %v", config.Scheme, err)
+	}
+
+	return signer, req.Header
+}
+
+func TestSignVerifyRoundTrip(t *testing.T) {
+	for _, config := range schemeConfigs {
+		t.Run(string(config.Scheme)+config.Header, func(t *testing.T) {
+			signer, header := sign(t, config)
+
+			verifier, ok := signer.(Verifier)
+			if !ok {
+				t.Fatalf("%s signer can't verify", config.Scheme)
+			}
+			if err := verifier.Verify(header, body); err != nil {
+				t.Errorf("Verify of a signed request: %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsChangedBody(t *testing.T) {
+	for _, config := range schemeConfigs {
+		if config.Scheme == SchemeChargebee {
+			// Basic auth doesn't cover the body
+			continue
+		}
+		t.Run(string(config.Scheme)+config.Header, func(t *testing.T) {
+			signer, header := sign(t, config)
+
+			changed := []byte(strings.Replace(string(body), "evt_1", "evt_2", 1))
+			if err := signer.(Verifier).Verify(header, changed); !errors.Is(err, ErrMismatch) {
+				t.Errorf("Verify of a changed body = %v, want ErrMismatch", err)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsOtherSecret(t *testing.T) {
+	for _, config := range schemeConfigs {
+		t.Run(string(config.Scheme)+config.Header, func(t *testing.T) {
+			_, header := sign(t, config)
+
+			other := config
+			other.Password = "other"
+			other.Secret = "other"
+			if config.Scheme == SchemeStandardWebhooks {
+				other.Secret = "whsec_b3RoZXI="
+			}
+			verifier, err := New(other)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := verifier.(Verifier).Verify(header, body); err == nil {
+				t.Error("Verify with another secret succeeded")
+			}
+		})
+	}
+}
+
+func TestVerifyMissingHeaders(t *testing.T) {
+	for _, config := range schemeConfigs {
+		t.Run(string(config.Scheme)+config.Header, func(t *testing.T) {
+			signer, _ := sign(t, config)
+			if err := signer.(Verifier).Verify(http.Header{}, body); err == nil {
+				t.Error("Verify without headers succeeded")
+			}
+		})
+	}
+}
+
+// Signatures published by the providers, so a scheme that only agrees with
+// itself still fails
+func TestVerifyKnownSignatures(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		header http.Header
+		body   string
+	}{
+		{
+			name:   "github",
+			config: Config{Scheme: SchemeGitHub, Secret: "It's a Secret to Everybody"},
+			header: http.Header{"X-Hub-Signature-256": {"sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"}},
+			body:   "Hello, World!",
+		},
+		{
+			name:   "standard_webhooks",
+			config: Config{Scheme: SchemeStandardWebhooks, Secret: "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw"},
+			header: http.Header{
+				"Webhook-Id":        {"msg_p5jXN8AQM9LWM0D4loKWxJek"},
+				"Webhook-Timestamp": {"1614265330"},
+				"Webhook-Signature": {"v1,g0hM9SsE+OTPJTGt/tmIKtSyZlE3uFJELVlNIOLJ1OE="},
+			},
+			body: `{"test": 2432232314}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := New(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := signer.(Verifier).Verify(tt.header, []byte(tt.body)); err != nil {
+				t.Errorf("Verify: %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyStripeRotatedSecrets(t *testing.T) {
+	_, header := sign(t, Config{Scheme: SchemeStripe, Secret: "whsec_new"})
+	header.Set("Stripe-Signature", header.Get("Stripe-Signature")+",v1=0000")
+
+	signer, err := New(Config{Scheme: SchemeStripe, Secret: "whsec_new"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := signer.(Verifier).Verify(header, body); err != nil {
+		t.Errorf("Verify with an extra signature: %v", err)
+	}
+}
+
+func TestVerifyNotSigned(t *testing.T) {
+	signer, err := New(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := signer.(Verifier).Verify(http.Header{}, body); !errors.Is(err, ErrNotSigned) {
+		t.Errorf("Verify without a scheme = %v, want ErrNotSigned", err)
+	}
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const attemptsDir = ".attempts"
+
+// Attempt records one outgoing delivery of a stored event, whether from a
+// replay or draft sent in the UI or from the delivery emulator
+type Attempt struct {
+	Event        string        `json:"event"`
+	Source       string        `json:"source"`
+	Target       string        `json:"target"`
+	Number       int           `json:"attempt"`
+	SentAt       time.Time     `json:"sent_at"`
+	StatusCode   int           `json:"status_code,omitempty"`
+	Duration     time.Duration `json:"duration"`
+	Error        string        `json:"error,omitempty"`
+	ResponseBody string        `json:"response_body,omitempty"`
+}
+
+func (a Attempt) Succeeded() bool {
+	return a.Error == "" && a.StatusCode >= 200 && a.StatusCode < 300
+}
+
+// AttemptsPath returns the file an event's attempts are logged to
+func (fs *FileStorage) AttemptsPath(event string) string {
+	name := strings.TrimSuffix(filepath.Base(event), filepath.Ext(event))
+	return filepath.Join(fs.baseDir, attemptsDir, name+".jsonl")
+}
+
+// RecordAttempt appends an attempt to the event's attempt log
+func (fs *FileStorage) RecordAttempt(attempt Attempt) error {
+	if fs.readOnly {
+		return ErrReadOnly
+	}
+
+	path := fs.AttemptsPath(attempt.Event)
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("creating attempts directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0640)
+	if err != nil {
+		return fmt.Errorf("opening attempts file: %w", err)
+	}
+	defer f.Close()
+
+	if err := json.NewEncoder(f).Encode(attempt); err != nil {
+		return fmt.Errorf("encoding attempt: %w", err)
+	}
+
+	return nil
+}
+
+// ListAttempts returns the recorded attempts for an event, oldest first
+func (fs *FileStorage) ListAttempts(event string) ([]Attempt, error) {
+	f, err := os.Open(fs.AttemptsPath(event))
+	if os.IsNotExist(err) {
+		return []Attempt{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening attempts file: %w", err)
+	}
+	defer f.Close()
+
+	var attempts []Attempt
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var attempt Attempt
+		if err := json.Unmarshal(scanner.Bytes(), &attempt); err != nil {
+			continue
+		}
+		attempts = append(attempts, attempt)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading attempts file: %w", err)
+	}
+
+	return attempts, nil
+}
@@ -0,0 +1,244 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lukeberry99/whook/internal/signing"
+)
+
+const draftsDir = ".drafts"
+
+// draftName matches the names CreateDraft gives drafts, when the draft was
+// created followed by the name of the event it was copied from
+var draftName = regexp.MustCompile(`^\d{8}-\d{6}_(.+)\.http$`)
+
+// draftSkippedHeaders are set again when a draft is sent, by the HTTP
+// client, so they'd only be stale in the draft
+var draftSkippedHeaders = []string{
+	"Content-Length", "Transfer-Encoding", "Connection", "Keep-Alive", "Te",
+	"Trailer", "Upgrade", "Proxy-Connection", "Host", "Accept-Encoding",
+}
+
+// Draft is an editable copy of a captured event. It's stored as a small
+// HTTP-like text file: a method line, header lines, a blank line and the body.
+type Draft struct {
+	Path    string
+	Service string
+	Method  string
+	Headers http.Header
+	Body    []byte
+}
+
+type DraftListItem struct {
+	Name    string
+	Path    string
+	Service string
+	SavedAt time.Time
+}
+
+// CreateDraft copies an event into a new draft file and returns its path,
+// the captured event itself is never modified. The captured headers are
+// copied too, except for the ones the HTTP client sets and the signatures,
+// including signatureHeader, which are signed again when the draft is sent.
+func (fs *FileStorage) CreateDraft(item EventListItem, signatureHeader string) (string, error) {
+	if fs.readOnly {
+		return "", ErrReadOnly
+	}
+
+	path := item.Path
+	if path == "" {
+		path = fs.GetFullPath(item.Filename)
+	}
+	event, err := LoadEvent(path)
+	if err != nil {
+		return "", err
+	}
+	body, err := fs.ReadEventBody(item)
+	if err != nil {
+		return "", err
+	}
+
+	service := item.ServiceName
+	if service == "" && fs.selectedService != "All" {
+		service = fs.selectedService
+	}
+
+	dir := filepath.Join(fs.baseDir, draftsDir, service)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", fmt.Errorf("creating drafts directory: %w", err)
+	}
+
+	name := fmt.Sprintf("%s_%s.http",
+		time.Now().Format("20060102-150405"),
+		strings.TrimSuffix(item.Filename, filepath.Ext(item.Filename)))
+
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, body, "", "  "); err != nil {
+		pretty.Reset()
+		pretty.Write(body)
+	}
+
+	headers := http.Header{}
+	for key, values := range event.Headers {
+		if isDraftSkipped(key) || signing.IsSignatureHeader(key, signatureHeader) {
+			continue
+		}
+		headers[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+	}
+	if headers.Get("Content-Type") == "" {
+		headers.Set("Content-Type", "application/json")
+	}
+
+	draft := &Draft{
+		Method:  http.MethodPost,
+		Headers: headers,
+		Body:    pretty.Bytes(),
+	}
+
+	draftPath := filepath.Join(dir, name)
+	if err := os.WriteFile(draftPath, FormatDraft(draft), 0640); err != nil {
+		return "", fmt.Errorf("writing draft: %w", err)
+	}
+
+	return draftPath, nil
+}
+
+func isDraftSkipped(header string) bool {
+	for _, skipped := range draftSkippedHeaders {
+		if strings.EqualFold(header, skipped) {
+			return true
+		}
+	}
+	return false
+}
+
+// DraftEvent returns the filename of the event a draft was copied from, or an
+// empty string for drafts that weren't created by CreateDraft
+func DraftEvent(name string) string {
+	m := draftName.FindStringSubmatch(name)
+	if m == nil {
+		return ""
+	}
+	return m[1] + ".json"
+}
+
+func (fs *FileStorage) ListDrafts() ([]DraftListItem, error) {
+	root := filepath.Join(fs.baseDir, draftsDir)
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return []DraftListItem{}, nil
+	}
+
+	var items []DraftListItem
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(info.Name(), ".http") {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+
+		var service string
+		if dir := filepath.Dir(relPath); dir != "." {
+			service = dir
+		}
+
+		items = append(items, DraftListItem{
+			Name:    info.Name(),
+			Path:    path,
+			Service: service,
+			SavedAt: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walking drafts directory: %w", err)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].SavedAt.After(items[j].SavedAt)
+	})
+
+	return items, nil
+}
+
+func (fs *FileStorage) ReadDraft(item DraftListItem) (*Draft, error) {
+	data, err := os.ReadFile(item.Path)
+	if err != nil {
+		return nil, fmt.Errorf("reading draft %s: %w", item.Path, err)
+	}
+
+	draft, err := ParseDraft(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing draft %s: %w", item.Name, err)
+	}
+	draft.Path = item.Path
+	draft.Service = item.Service
+
+	return draft, nil
+}
+
+func ParseDraft(data []byte) (*Draft, error) {
+	reader := textproto.NewReader(bufio.NewReader(bytes.NewReader(data)))
+
+	method, err := reader.ReadLine()
+	if err != nil {
+		return nil, fmt.Errorf("reading method line: %w", err)
+	}
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if method == "" {
+		return nil, fmt.Errorf("missing method on the first line")
+	}
+
+	headers, err := reader.ReadMIMEHeader()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("reading headers: %w", err)
+	}
+
+	var body bytes.Buffer
+	if _, err := body.ReadFrom(reader.R); err != nil {
+		return nil, fmt.Errorf("reading body: %w", err)
+	}
+
+	return &Draft{
+		Method:  method,
+		Headers: http.Header(headers),
+		Body:    bytes.TrimSpace(body.Bytes()),
+	}, nil
+}
+
+func FormatDraft(draft *Draft) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(draft.Method + "\n")
+
+	keys := make([]string, 0, len(draft.Headers))
+	for key := range draft.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range draft.Headers[key] {
+			fmt.Fprintf(&buf, "%s: %s\n", key, value)
+		}
+	}
+
+	buf.WriteString("\n")
+	buf.Write(draft.Body)
+	buf.WriteString("\n")
+
+	return buf.Bytes()
+}
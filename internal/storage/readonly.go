@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrReadOnly is returned by every write to a read-only store
+var ErrReadOnly = errors.New("storage is read-only")
+
+// OpenReadOnly opens an existing storage directory, such as an opened bundle
+// or a copy from a teammate, without ever writing to it. An empty path opens
+// the default storage directory.
+func OpenReadOnly(path string) (*FileStorage, error) {
+	if path == "" {
+		path = getWebhookDataDirectory()
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening storage directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("opening storage directory: %s is not a directory", path)
+	}
+
+	fs := &FileStorage{
+		root:     path,
+		baseDir:  path,
+		updates:  make(chan EventChanges, 1),
+		readOnly: true,
+	}
+
+	go fs.watchDirectory()
+
+	return fs, nil
+}
+
+func (fs *FileStorage) ReadOnly() bool {
+	return fs.readOnly
+}
+
+// BaseDir returns the directory events are stored in
+func (fs *FileStorage) BaseDir() string {
+	return fs.baseDir
+}
+
+// RelPath returns path relative to the storage directory
+func (fs *FileStorage) RelPath(path string) string {
+	rel, err := filepath.Rel(fs.baseDir, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
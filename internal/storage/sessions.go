@@ -0,0 +1,286 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	sessionsDir = ".sessions"
+	sessionFile = ".session.json"
+)
+
+var sessionName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Session is a namespace of events. The default session, with an empty name,
+// is the storage directory itself, named sessions live under .sessions.
+type Session struct {
+	Name      string        `json:"-"`
+	Dir       string        `json:"-"`
+	CreatedAt time.Time     `json:"created_at"`
+	Retention time.Duration `json:"retention,omitempty"`
+}
+
+// DisplayName returns the session's name, or "default" for the default session
+func (s Session) DisplayName() string {
+	if s.Name == "" {
+		return "default"
+	}
+	return s.Name
+}
+
+func (fs *FileStorage) sessionDir(name string) string {
+	if name == "" {
+		return fs.root
+	}
+	return filepath.Join(fs.root, sessionsDir, name)
+}
+
+// Root returns the storage directory every session lives in
+func (fs *FileStorage) Root() string {
+	return fs.root
+}
+
+// Session returns the name of the current session, empty for the default one
+func (fs *FileStorage) Session() string {
+	return fs.session
+}
+
+// SetSession switches the session events are stored in and listed from,
+// creating it when it doesn't exist yet
+func (fs *FileStorage) SetSession(name string) error {
+	if name != "" && !sessionName.MatchString(name) {
+		return fmt.Errorf("invalid session name %q, use letters, numbers, dots, dashes and underscores", name)
+	}
+
+	dir := fs.sessionDir(name)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if fs.readOnly {
+			return fmt.Errorf("session %s doesn't exist", name)
+		}
+		if err := fs.writeSession(Session{Name: name, Dir: dir, CreatedAt: time.Now()}); err != nil {
+			return err
+		}
+	}
+
+	fs.session = name
+	fs.baseDir = dir
+
+	return nil
+}
+
+// InSession returns a view of another session that shares nothing with fs,
+// such as the selected service, for reading it alongside the current one
+func (fs *FileStorage) InSession(name string) (*FileStorage, error) {
+	dir := fs.sessionDir(name)
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("session %s doesn't exist", name)
+	}
+
+	return &FileStorage{
+		root:     fs.root,
+		baseDir:  dir,
+		session:  name,
+		updates:  make(chan EventChanges, 1),
+		readOnly: fs.readOnly,
+	}, nil
+}
+
+// Sessions lists the default session followed by the named ones
+func (fs *FileStorage) Sessions() ([]Session, error) {
+	sessions := []Session{fs.readSession("")}
+
+	entries, err := os.ReadDir(filepath.Join(fs.root, sessionsDir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("listing sessions: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() && sessionName.MatchString(entry.Name()) {
+			sessions = append(sessions, fs.readSession(entry.Name()))
+		}
+	}
+
+	sort.SliceStable(sessions[1:], func(i, j int) bool {
+		return sessions[i+1].Name < sessions[j+1].Name
+	})
+
+	return sessions, nil
+}
+
+func (fs *FileStorage) readSession(name string) Session {
+	session := Session{Name: name, Dir: fs.sessionDir(name)}
+
+	data, err := os.ReadFile(filepath.Join(session.Dir, sessionFile))
+	if err == nil {
+		_ = json.Unmarshal(data, &session)
+	}
+
+	return session
+}
+
+func (fs *FileStorage) writeSession(session Session) error {
+	if fs.readOnly {
+		return ErrReadOnly
+	}
+
+	if err := os.MkdirAll(session.Dir, 0750); err != nil {
+		return fmt.Errorf("creating session directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding session: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(session.Dir, sessionFile), data, 0640); err != nil {
+		return fmt.Errorf("writing session: %w", err)
+	}
+
+	return nil
+}
+
+// SetSessionRetention sets how long a session keeps its events, zero falls
+// back to the configured default
+func (fs *FileStorage) SetSessionRetention(name string, retention time.Duration) error {
+	if _, err := os.Stat(fs.sessionDir(name)); err != nil {
+		return fmt.Errorf("session %s doesn't exist", name)
+	}
+
+	session := fs.readSession(name)
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = time.Now()
+	}
+	session.Retention = retention
+
+	return fs.writeSession(session)
+}
+
+// RemoveSession deletes a named session and everything in it
+func (fs *FileStorage) RemoveSession(name string) error {
+	if fs.readOnly {
+		return ErrReadOnly
+	}
+	if name == "" {
+		return fmt.Errorf("the default session can't be removed")
+	}
+	if name == fs.session {
+		return fmt.Errorf("session %s is in use", name)
+	}
+
+	if err := os.RemoveAll(fs.sessionDir(name)); err != nil {
+		return fmt.Errorf("removing session %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// Prune deletes the current session's events stored before cutoff, along
+// with their tags and attempt logs, and returns the deleted filenames. The
+// time an event was stored is used rather than when it was received, so
+// imported events get the full retention too.
+func (fs *FileStorage) Prune(cutoff time.Time) ([]string, error) {
+	if fs.readOnly {
+		return nil, ErrReadOnly
+	}
+
+	items, err := fs.ListAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var pruned []string
+	for _, item := range items {
+		info, err := os.Stat(item.Path)
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+
+		if err := os.Remove(item.Path); err != nil {
+			return pruned, fmt.Errorf("removing %s: %w", item.Filename, err)
+		}
+		if err := os.Remove(fs.AttemptsPath(item.Filename)); err != nil && !os.IsNotExist(err) {
+			return pruned, fmt.Errorf("removing attempts for %s: %w", item.Filename, err)
+		}
+		if err := fs.SetTags(item.Filename, nil); err != nil {
+			return pruned, err
+		}
+
+		pruned = append(pruned, item.Filename)
+	}
+
+	return pruned, nil
+}
+
+// PruneSessions applies each session's retention, or defaultRetention when
+// the session doesn't set one, and returns how many events were deleted by
+// session. A zero retention keeps events forever.
+func (fs *FileStorage) PruneSessions(defaultRetention time.Duration) (map[string]int, error) {
+	sessions, err := fs.Sessions()
+	if err != nil {
+		return nil, err
+	}
+
+	pruned := map[string]int{}
+	for _, session := range sessions {
+		retention := session.Retention
+		if retention == 0 {
+			retention = defaultRetention
+		}
+		if retention <= 0 {
+			continue
+		}
+
+		view, err := fs.InSession(session.Name)
+		if err != nil {
+			return pruned, err
+		}
+		deleted, err := view.Prune(time.Now().Add(-retention))
+		if len(deleted) > 0 {
+			pruned[session.DisplayName()] = len(deleted)
+		}
+		if err != nil {
+			return pruned, fmt.Errorf("pruning session %s: %w", session.DisplayName(), err)
+		}
+	}
+
+	return pruned, nil
+}
+
+// ForEvent returns the session an event file belongs to, so its tags and
+// attempts are kept beside it. Files outside the storage directory belong to
+// the current session.
+func (fs *FileStorage) ForEvent(path string) *FileStorage {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fs
+	}
+	root, err := filepath.Abs(fs.root)
+	if err != nil {
+		return fs
+	}
+
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return fs
+	}
+
+	name := ""
+	if parts := strings.Split(rel, string(filepath.Separator)); len(parts) > 2 && parts[0] == sessionsDir {
+		name = parts[1]
+	}
+	if name == fs.session {
+		return fs
+	}
+
+	view, err := fs.InSession(name)
+	if err != nil {
+		return fs
+	}
+	return view
+}
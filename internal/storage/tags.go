@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+const tagsFile = ".tags.json"
+
+var tagsMu sync.Mutex
+
+func (fs *FileStorage) tagsPath() string {
+	return filepath.Join(fs.baseDir, tagsFile)
+}
+
+// AllTags returns the tags of every tagged event, keyed by event filename
+func (fs *FileStorage) AllTags() (map[string][]string, error) {
+	tagsMu.Lock()
+	defer tagsMu.Unlock()
+
+	return fs.readTags()
+}
+
+func (fs *FileStorage) Tags(filename string) ([]string, error) {
+	tags, err := fs.AllTags()
+	if err != nil {
+		return nil, err
+	}
+	return tags[filename], nil
+}
+
+// SetTags replaces an event's tags, an empty list removes them
+func (fs *FileStorage) SetTags(filename string, tags []string) error {
+	if fs.readOnly {
+		return ErrReadOnly
+	}
+
+	tagsMu.Lock()
+	defer tagsMu.Unlock()
+
+	all, err := fs.readTags()
+	if err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		delete(all, filename)
+	} else {
+		unique := map[string]bool{}
+		var cleaned []string
+		for _, tag := range tags {
+			if tag != "" && !unique[tag] {
+				unique[tag] = true
+				cleaned = append(cleaned, tag)
+			}
+		}
+		sort.Strings(cleaned)
+		all[filename] = cleaned
+	}
+
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding tags: %w", err)
+	}
+	if err := os.WriteFile(fs.tagsPath(), data, 0640); err != nil {
+		return fmt.Errorf("writing tags: %w", err)
+	}
+
+	return nil
+}
+
+func (fs *FileStorage) readTags() (map[string][]string, error) {
+	tags := map[string][]string{}
+
+	data, err := os.ReadFile(fs.tagsPath())
+	if os.IsNotExist(err) {
+		return tags, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading tags: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return nil, fmt.Errorf("decoding tags: %w", err)
+	}
+
+	return tags, nil
+}
@@ -1,38 +1,111 @@
 package storage
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 )
 
 type FileStorage struct {
+	// root is the configured storage directory and baseDir the directory of
+	// the current session within it
+	root            string
 	baseDir         string
-	updates         chan []EventListItem
+	session         string
+	updates         chan EventChanges
 	selectedService string
+	readOnly        bool
+
+	// received caches when each event file was received, so listing only
+	// reads files that are new or have changed
+	receivedMu sync.Mutex
+	received   map[string]receivedTime
+}
+
+type receivedTime struct {
+	modTime time.Time
+	size    int64
+	time    time.Time
+	ok      bool
 }
 
 type WebhookEvent struct {
 	ReceivedAt time.Time
 	RawEvent   interface{}
+	Method     string
+	Path       string
+	Query      string
+	Headers    http.Header
+	Response   *StoredResponse
+	Imported   *ImportInfo
+}
+
+// StoredResponse is the response whook sent back to the provider
+type StoredResponse struct {
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// ImportInfo marks an event that was imported rather than received
+type ImportInfo struct {
+	Format     string    `json:"format"`
+	Source     string    `json:"source,omitempty"`
+	ImportedAt time.Time `json:"imported_at"`
+}
+
+// StoredEvent is the decoded contents of an event file. Events stored by
+// older versions only have ReceivedAt and Event set.
+type StoredEvent struct {
+	ReceivedAt time.Time       `json:"received_at"`
+	Method     string          `json:"method,omitempty"`
+	Path       string          `json:"path,omitempty"`
+	Query      string          `json:"query,omitempty"`
+	Headers    http.Header     `json:"headers,omitempty"`
+	RawBody    []byte          `json:"raw_body,omitempty"`
+	Response   *StoredResponse `json:"response,omitempty"`
+	Imported   *ImportInfo     `json:"imported,omitempty"`
+	Event      json.RawMessage `json:"event"`
+}
+
+// Body returns the exact bytes that were received when they were recorded,
+// otherwise the compacted event payload
+func (e *StoredEvent) Body() []byte {
+	if len(e.RawBody) > 0 {
+		return e.RawBody
+	}
+
+	var body bytes.Buffer
+	if err := json.Compact(&body, e.Event); err != nil {
+		return e.Event
+	}
+	return body.Bytes()
 }
 
 type WebhookStorage interface {
 	Store(event *WebhookEvent, rawBody []byte) (string, error)
+	SelectedService() string
 }
 
 type EventListItem struct {
 	Filename    string
 	ReceivedAt  string
 	ServiceName string
+	Path        string
+	Time        time.Time
+	// ModTime is when the event file last changed, such as by an edit
+	ModTime time.Time
 }
 
 func getWebhookDataDirectory() string {
@@ -58,8 +131,9 @@ func NewFileStorage(customPath string) (*FileStorage, error) {
 	}
 
 	fs := &FileStorage{
+		root:    baseDir,
 		baseDir: baseDir,
-		updates: make(chan []EventListItem, 1),
+		updates: make(chan EventChanges, 1),
 	}
 
 	go fs.watchDirectory()
@@ -67,10 +141,66 @@ func NewFileStorage(customPath string) (*FileStorage, error) {
 	return fs, nil
 }
 
-func (fs *FileStorage) WatchEvents() <-chan []EventListItem {
+// EventChanges describes what changed in the listed events, so a listing
+// can be updated without reading the whole directory again
+type EventChanges struct {
+	// All is set when the events have to be listed again, such as after the
+	// selected service changes or a directory is moved
+	All bool
+	// Changed lists events that were added or written, Removed the paths of
+	// events that are gone or are no longer listed
+	Changed []EventListItem
+	Removed []string
+	// Attempts is set when delivery attempts were recorded
+	Attempts bool
+}
+
+func (fs *FileStorage) WatchEvents() <-chan EventChanges {
 	return fs.updates
 }
 
+// changesFor turns the paths reported by the watcher into changes of the
+// listed events
+func (fs *FileStorage) changesFor(paths map[string]bool) EventChanges {
+	var changes EventChanges
+	for path := range paths {
+		rel, err := filepath.Rel(fs.baseDir, path)
+		if err != nil {
+			continue
+		}
+
+		// Other sessions are under .. or the hidden sessions directory
+		hidden := ""
+		for _, part := range strings.Split(rel, string(filepath.Separator)) {
+			if strings.HasPrefix(part, ".") {
+				hidden = part
+				break
+			}
+		}
+		switch {
+		case hidden == attemptsDir:
+			changes.Attempts = true
+			continue
+		case hidden != "":
+			// Whook's own data, such as drafts and tags, or another session
+			continue
+		case !strings.HasSuffix(path, ".json"):
+			// A directory that was created, moved or removed with its events
+			if info, err := os.Stat(path); err != nil || info.IsDir() {
+				changes.All = true
+			}
+			continue
+		}
+
+		if item, ok := fs.EventItem(path); ok {
+			changes.Changed = append(changes.Changed, item)
+		} else {
+			changes.Removed = append(changes.Removed, path)
+		}
+	}
+	return changes
+}
+
 func (fs *FileStorage) watchDirectory() {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -79,14 +209,15 @@ func (fs *FileStorage) watchDirectory() {
 	}
 	defer watcher.Close()
 
-	// Watch base directory
-	if err := watcher.Add(fs.baseDir); err != nil {
+	// Watch the storage directory, sessions live beneath it so switching
+	// between them doesn't need a new watcher
+	if err := watcher.Add(fs.root); err != nil {
 		fmt.Printf("Error watching base directory: %v\n", err)
 		return
 	}
 
 	// Watch all existing service directories
-	if err := filepath.Walk(fs.baseDir, func(path string, info os.FileInfo, err error) error {
+	if err := filepath.Walk(fs.root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
@@ -100,7 +231,11 @@ func (fs *FileStorage) watchDirectory() {
 		fmt.Printf("Error setting up directory watchers: %v\n", err)
 	}
 
+	// Paths are gathered until the watcher has been quiet for a moment, then
+	// sent as one set of changes
 	var timer *time.Timer
+	var pendingMu sync.Mutex
+	pending := map[string]bool{}
 	for {
 		select {
 		case event, ok := <-watcher.Events:
@@ -117,16 +252,23 @@ func (fs *FileStorage) watchDirectory() {
 			}
 
 			if event.Op&(fsnotify.Create|fsnotify.Remove|fsnotify.Rename|fsnotify.Write) != 0 {
+				pendingMu.Lock()
+				pending[event.Name] = true
+				pendingMu.Unlock()
+
 				if timer != nil {
 					timer.Stop()
 				}
 				timer = time.AfterFunc(100*time.Millisecond, func() {
-					items, err := fs.ListEvents()
-					if err != nil {
-						fmt.Printf("Error listing events: %v\n", err)
-						return
+					pendingMu.Lock()
+					paths := pending
+					pending = map[string]bool{}
+					pendingMu.Unlock()
+
+					changes := fs.changesFor(paths)
+					if changes.All || changes.Attempts || len(changes.Changed) > 0 || len(changes.Removed) > 0 {
+						fs.updates <- changes
 					}
-					fs.updates <- items
 				})
 			}
 		case err, ok := <-watcher.Errors:
@@ -139,68 +281,153 @@ func (fs *FileStorage) watchDirectory() {
 }
 
 func (fs *FileStorage) ListEvents() ([]EventListItem, error) {
-	searchDir := fs.baseDir
-	if fs.selectedService != "" && fs.selectedService != "All" {
-		searchDir = filepath.Join(fs.baseDir, fs.selectedService)
+	searchDir := fs.listingDir()
+	if searchDir != fs.baseDir && !fs.readOnly {
 		if err := os.MkdirAll(searchDir, 0750); err != nil {
 			return nil, fmt.Errorf("creating service directory: %w", err)
 		}
 	}
 
-	if _, err := os.Stat(searchDir); os.IsNotExist(err) {
-		return []EventListItem{}, nil
-	}
+	return fs.listDir(searchDir)
+}
 
-	var items []EventListItem
+// listingDir is the directory ListEvents lists, the selected service's or
+// the session's for every service
+func (fs *FileStorage) listingDir() string {
+	if fs.selectedService != "" && fs.selectedService != "All" {
+		return filepath.Join(fs.baseDir, fs.selectedService)
+	}
+	return fs.baseDir
+}
 
-	err := filepath.Walk(searchDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil
+// EventItem returns the listing of the event file at path, if ListEvents
+// would list it
+func (fs *FileStorage) EventItem(path string) (EventListItem, bool) {
+	searchDir := fs.listingDir()
+	rel, err := filepath.Rel(searchDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return EventListItem{}, false
+	}
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		if strings.HasPrefix(part, ".") {
+			return EventListItem{}, false
 		}
+	}
 
-		if info.IsDir() {
-			return nil
-		}
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return EventListItem{}, false
+	}
+	return fs.listItem(path, info)
+}
 
-		if !strings.HasSuffix(info.Name(), ".json") {
-			return nil
-		}
+// listItem reads the listing of an event file, it's false for files that
+// aren't events
+func (fs *FileStorage) listItem(path string, info os.FileInfo) (EventListItem, bool) {
+	if !strings.HasSuffix(info.Name(), ".json") || strings.HasPrefix(info.Name(), ".") {
+		return EventListItem{}, false
+	}
 
-		relPath, err := filepath.Rel(fs.baseDir, path)
-		if err != nil {
-			return nil
-		}
+	relPath, err := filepath.Rel(fs.baseDir, path)
+	if err != nil {
+		return EventListItem{}, false
+	}
 
-		pathParts := strings.Split(filepath.Dir(relPath), string(filepath.Separator))
-		var serviceName string
-		if len(pathParts) > 0 && pathParts[0] != "." {
-			serviceName = pathParts[0]
-		}
+	pathParts := strings.Split(filepath.Dir(relPath), string(filepath.Separator))
+	var serviceName string
+	if len(pathParts) > 0 && pathParts[0] != "." {
+		serviceName = pathParts[0]
+	}
 
-		data, err := os.ReadFile(path)
-		if err != nil {
-			return nil
-		}
+	timestamp, ok := fs.receivedAt(path, info)
+	if !ok {
+		return EventListItem{}, false
+	}
+
+	return EventListItem{
+		Filename:    filepath.Base(path),
+		ReceivedAt:  timestamp.Format("02/01/2006 15:04:05"),
+		ServiceName: serviceName,
+		Path:        path,
+		Time:        timestamp,
+		ModTime:     info.ModTime(),
+	}, true
+}
+
+// ListAll lists the events of every service, whichever one is selected
+func (fs *FileStorage) ListAll() ([]EventListItem, error) {
+	return fs.listDir(fs.baseDir)
+}
 
+// receivedAt returns when the event in path was received, reading the file
+// only when it isn't cached or has changed since
+func (fs *FileStorage) receivedAt(path string, info os.FileInfo) (time.Time, bool) {
+	fs.receivedMu.Lock()
+	cached, found := fs.received[path]
+	fs.receivedMu.Unlock()
+	if found && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.time, cached.ok
+	}
+
+	entry := receivedTime{modTime: info.ModTime(), size: info.Size()}
+	if data, err := os.ReadFile(path); err == nil {
 		var fileData struct {
 			ReceivedAt string `json:"received_at"`
 		}
-		if err := json.Unmarshal(data, &fileData); err != nil {
-			return nil
+		if err := json.Unmarshal(data, &fileData); err == nil {
+			entry.time, err = time.Parse(time.RFC3339, fileData.ReceivedAt)
+			entry.ok = err == nil
 		}
+	}
+
+	fs.receivedMu.Lock()
+	if fs.received == nil {
+		fs.received = map[string]receivedTime{}
+	}
+	fs.received[path] = entry
+	fs.receivedMu.Unlock()
 
-		timestamp, err := time.Parse(time.RFC3339, fileData.ReceivedAt)
+	return entry.time, entry.ok
+}
+
+// forgetMissing drops the cached times of files under dir that no longer exist
+func (fs *FileStorage) forgetMissing(dir string, seen map[string]bool) {
+	prefix := dir + string(filepath.Separator)
+
+	fs.receivedMu.Lock()
+	defer fs.receivedMu.Unlock()
+	for path := range fs.received {
+		if strings.HasPrefix(path, prefix) && !seen[path] {
+			delete(fs.received, path)
+		}
+	}
+}
+
+func (fs *FileStorage) listDir(searchDir string) ([]EventListItem, error) {
+	if _, err := os.Stat(searchDir); os.IsNotExist(err) {
+		return []EventListItem{}, nil
+	}
+
+	var items []EventListItem
+	seen := map[string]bool{}
+
+	err := filepath.Walk(searchDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
 
-		formattedTime := timestamp.Format("02/01/2006 15:04:05")
+		if info.IsDir() {
+			// Hidden directories hold whook's own data, such as drafts
+			if path != searchDir && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 
-		items = append(items, EventListItem{
-			Filename:    filepath.Base(path),
-			ReceivedAt:  formattedTime,
-			ServiceName: serviceName,
-		})
+		seen[path] = true
+		if item, ok := fs.listItem(path, info); ok {
+			items = append(items, item)
+		}
 
 		return nil
 	})
@@ -208,6 +435,7 @@ func (fs *FileStorage) ListEvents() ([]EventListItem, error) {
 	if err != nil {
 		return nil, fmt.Errorf("walking directory: %w", err)
 	}
+	fs.forgetMissing(searchDir, seen)
 
 	// Sort by filename (which includes timestamp), newest first
 	sort.Slice(items, func(i, j int) bool {
@@ -228,28 +456,101 @@ func (fs *FileStorage) ReadEvent(filename string) ([]byte, error) {
 	return data, nil
 }
 
+// LoadEvent reads and decodes an event file
+func LoadEvent(path string) (*StoredEvent, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading file %s: %w", path, err)
+	}
+
+	var event StoredEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, fmt.Errorf("decoding event file %s: %w", path, err)
+	}
+
+	return &event, nil
+}
+
+// ReadEventBody returns just the event payload, which is what the provider
+// originally sent and what a replay should send again. The item's path is
+// used when it has one, so events listed under "All" resolve to their own
+// service directory rather than the selected one.
+func (fs *FileStorage) ReadEventBody(item EventListItem) ([]byte, error) {
+	path := item.Path
+	if path == "" {
+		path = fs.GetFullPath(item.Filename)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading file %s: %w", path, err)
+	}
+
+	return ExtractEventBody(data)
+}
+
+// ExtractEventBody pulls the compacted event payload out of a stored event file
+func ExtractEventBody(data []byte) ([]byte, error) {
+	var fileData struct {
+		Event json.RawMessage `json:"event"`
+	}
+	if err := json.Unmarshal(data, &fileData); err != nil {
+		return nil, fmt.Errorf("decoding event file: %w", err)
+	}
+
+	var body bytes.Buffer
+	if err := json.Compact(&body, fileData.Event); err != nil {
+		return nil, fmt.Errorf("compacting event body: %w", err)
+	}
+
+	return body.Bytes(), nil
+}
+
 func (fs *FileStorage) Store(event *WebhookEvent, rawBody []byte) (string, error) {
+	return fs.StoreAs(fs.SelectedService(), event, rawBody)
+}
+
+// StoreBody decodes a raw JSON payload and stores it under service
+func (fs *FileStorage) StoreBody(service string, receivedAt time.Time, body []byte) (string, error) {
+	var rawJSON interface{}
+	if err := json.Unmarshal(body, &rawJSON); err != nil {
+		return "", fmt.Errorf("decoding event body: %w", err)
+	}
+
+	return fs.StoreAs(service, &WebhookEvent{
+		ReceivedAt: receivedAt,
+		RawEvent:   rawJSON,
+	}, body)
+}
+
+// StoreAs stores an event under a specific service rather than the selected
+// one, an empty service stores it in the base directory
+func (fs *FileStorage) StoreAs(service string, event *WebhookEvent, rawBody []byte) (string, error) {
+	if fs.readOnly {
+		return "", ErrReadOnly
+	}
+
 	storageDir := fs.baseDir
-	if fs.selectedService != "" && fs.selectedService != "All" {
-		storageDir = filepath.Join(fs.baseDir, fs.selectedService)
+	if service != "" {
+		storageDir = filepath.Join(fs.baseDir, service)
 	}
 
 	if err := os.MkdirAll(storageDir, 0750); err != nil {
 		return "", fmt.Errorf("creating storage directory: %w", err)
 	}
 
-	filename := filepath.Join(storageDir, fmt.Sprintf("%s_%s.json",
+	name := fmt.Sprintf("%s_%s",
 		event.ReceivedAt.Format("150405"),
-		fs.generateUniqueFilename(rawBody)))
+		fs.generateUniqueFilename(rawBody))
 
-	if fs.selectedService != "" && fs.selectedService != "All" {
-		filename = filepath.Join(storageDir, fmt.Sprintf("%s_%s_%s.json",
+	if service != "" {
+		name = fmt.Sprintf("%s_%s_%s",
 			event.ReceivedAt.Format("150405"),
-			fs.selectedService,
-			fs.generateUniqueFilename(rawBody)))
+			service,
+			fs.generateUniqueFilename(rawBody))
 	}
 
-	f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY, 0640)
+	f, filename, err := createUnique(filepath.Join(storageDir, name))
 	if err != nil {
 		return "", fmt.Errorf("opening file: %w", err)
 	}
@@ -262,6 +563,20 @@ func (fs *FileStorage) Store(event *WebhookEvent, rawBody []byte) (string, error
 		"received_at": event.ReceivedAt.Format(time.RFC3339),
 		"event":       event.RawEvent,
 	}
+	// Request details are only known for webhooks received over HTTP
+	if event.Method != "" {
+		data["method"] = event.Method
+		data["path"] = event.Path
+		data["query"] = event.Query
+		data["headers"] = event.Headers
+		data["raw_body"] = rawBody
+	}
+	if event.Response != nil {
+		data["response"] = event.Response
+	}
+	if event.Imported != nil {
+		data["imported"] = event.Imported
+	}
 
 	if err := encoder.Encode(data); err != nil {
 		return "", fmt.Errorf("encoding JSON: %w", err)
@@ -271,9 +586,8 @@ func (fs *FileStorage) Store(event *WebhookEvent, rawBody []byte) (string, error
 	go func() {
 		// Small delay to ensure file is written
 		time.Sleep(50 * time.Millisecond)
-		items, err := fs.ListEvents()
-		if err == nil {
-			fs.updates <- items
+		if item, ok := fs.EventItem(filename); ok {
+			fs.updates <- EventChanges{Changed: []EventListItem{item}}
 		}
 	}()
 
@@ -287,14 +601,40 @@ func (fs *FileStorage) GetFullPath(filename string) string {
 	return fmt.Sprintf("%s/%s", fs.baseDir, filename)
 }
 
+// SelectedService returns the service incoming webhooks are stored under, or
+// an empty string when they go to the base directory
+func (fs *FileStorage) SelectedService() string {
+	if fs.selectedService == "All" {
+		return ""
+	}
+	return fs.selectedService
+}
+
 func (fs *FileStorage) SetSelectedService(service string) {
 	fs.selectedService = service
 
 	// Trigger an update of the file browser when the selected service changes
-	items, err := fs.ListEvents()
-	if err == nil {
-		fs.updates <- items
+	fs.updates <- EventChanges{All: true}
+}
+
+// createUnique creates base.json, or base_2.json and so on when it exists.
+// Names only hold the time of day and a hash of the payload, so the same
+// payload received at the same time on another day, or imported twice,
+// would otherwise overwrite the earlier event.
+func createUnique(base string) (*os.File, string, error) {
+	for i := 1; i <= 1000; i++ {
+		filename := base + ".json"
+		if i > 1 {
+			filename = fmt.Sprintf("%s_%d.json", base, i)
+		}
+
+		f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0640)
+		if os.IsExist(err) {
+			continue
+		}
+		return f, filename, err
 	}
+	return nil, "", fmt.Errorf("too many events named %s", filepath.Base(base))
 }
 
 // Generate a hash of the request payload for unique filenames
@@ -0,0 +1,217 @@
+package transform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"text/template"
+
+	"github.com/itchyny/gojq"
+	"github.com/lukeberry99/whook/internal/config"
+)
+
+// Message is the outgoing request that transforms operate on
+type Message struct {
+	URL     string
+	Method  string
+	Headers http.Header
+	Body    []byte
+}
+
+type step interface {
+	apply(msg *Message) error
+}
+
+// Pipeline runs a service's transforms in the order they're configured
+type Pipeline struct {
+	steps []step
+}
+
+func New(configs []config.TransformConfig) (*Pipeline, error) {
+	pipeline := &Pipeline{}
+
+	for i, cfg := range configs {
+		s, err := newStep(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("transform %d: %w", i+1, err)
+		}
+		pipeline.steps = append(pipeline.steps, s)
+	}
+
+	return pipeline, nil
+}
+
+func newStep(cfg config.TransformConfig) (step, error) {
+	set := 0
+	for _, configured := range []bool{cfg.JQ != "", cfg.Template != "", cfg.Headers != nil, cfg.URL != nil} {
+		if configured {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, fmt.Errorf("exactly one of jq, template, headers or url must be set")
+	}
+
+	switch {
+	case cfg.JQ != "":
+		return newJQStep(cfg.JQ)
+	case cfg.Template != "":
+		return newTemplateStep(cfg.Template)
+	case cfg.Headers != nil:
+		return headerStep{cfg: *cfg.Headers}, nil
+	default:
+		return newURLStep(*cfg.URL)
+	}
+}
+
+func (p *Pipeline) Apply(msg Message) (Message, error) {
+	out := Message{
+		URL:     msg.URL,
+		Method:  msg.Method,
+		Headers: msg.Headers.Clone(),
+		Body:    msg.Body,
+	}
+	if out.Headers == nil {
+		out.Headers = http.Header{}
+	}
+
+	if p == nil {
+		return out, nil
+	}
+
+	for i, s := range p.steps {
+		if err := s.apply(&out); err != nil {
+			return Message{}, fmt.Errorf("transform %d: %w", i+1, err)
+		}
+	}
+
+	return out, nil
+}
+
+type jqStep struct {
+	code *gojq.Code
+}
+
+func newJQStep(expression string) (step, error) {
+	query, err := gojq.Parse(expression)
+	if err != nil {
+		return nil, fmt.Errorf("parsing jq expression: %w", err)
+	}
+
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return nil, fmt.Errorf("compiling jq expression: %w", err)
+	}
+
+	return jqStep{code: code}, nil
+}
+
+func (s jqStep) apply(msg *Message) error {
+	var input interface{}
+	if err := json.Unmarshal(msg.Body, &input); err != nil {
+		return fmt.Errorf("jq requires a JSON body: %w", err)
+	}
+
+	iter := s.code.Run(input)
+	result, ok := iter.Next()
+	if !ok {
+		return fmt.Errorf("jq expression produced no output")
+	}
+	if err, isErr := result.(error); isErr {
+		return fmt.Errorf("running jq expression: %w", err)
+	}
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("encoding jq output: %w", err)
+	}
+	msg.Body = body
+
+	return nil
+}
+
+type templateStep struct {
+	tmpl *template.Template
+}
+
+func newTemplateStep(text string) (step, error) {
+	tmpl, err := template.New("transform").
+		Option("missingkey=zero").
+		Funcs(template.FuncMap{
+			"json": func(v interface{}) (string, error) {
+				data, err := json.Marshal(v)
+				return string(data), err
+			},
+		}).
+		Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %w", err)
+	}
+
+	return templateStep{tmpl: tmpl}, nil
+}
+
+// Templates are executed with the decoded payload as dot
+func (s templateStep) apply(msg *Message) error {
+	var payload interface{}
+	if err := json.Unmarshal(msg.Body, &payload); err != nil {
+		return fmt.Errorf("template requires a JSON body: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := s.tmpl.Execute(&buf, payload); err != nil {
+		return fmt.Errorf("executing template: %w", err)
+	}
+	msg.Body = buf.Bytes()
+
+	return nil
+}
+
+type headerStep struct {
+	cfg config.HeaderTransformConfig
+}
+
+// Headers are removed, then renamed, then added
+func (s headerStep) apply(msg *Message) error {
+	for _, name := range s.cfg.Remove {
+		msg.Headers.Del(name)
+	}
+
+	for from, to := range s.cfg.Rename {
+		values := msg.Headers.Values(from)
+		if len(values) == 0 {
+			continue
+		}
+		msg.Headers.Del(from)
+		for _, value := range values {
+			msg.Headers.Add(to, value)
+		}
+	}
+
+	for name, value := range s.cfg.Add {
+		msg.Headers.Set(name, value)
+	}
+
+	return nil
+}
+
+type urlStep struct {
+	match   *regexp.Regexp
+	replace string
+}
+
+func newURLStep(cfg config.URLRewriteConfig) (step, error) {
+	match, err := regexp.Compile(cfg.Match)
+	if err != nil {
+		return nil, fmt.Errorf("compiling url match: %w", err)
+	}
+
+	return urlStep{match: match, replace: cfg.Replace}, nil
+}
+
+func (s urlStep) apply(msg *Message) error {
+	msg.URL = s.match.ReplaceAllString(msg.URL, s.replace)
+	return nil
+}
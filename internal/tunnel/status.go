@@ -0,0 +1,66 @@
+package tunnel
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Status records whether the tunnel is up so the UI can show it. A nil
+// Status reports the tunnel as down.
+type Status struct {
+	mu       sync.Mutex
+	provider string
+	url      string
+	upSince  time.Time
+	restart  func() (string, error)
+}
+
+// Up records that the tunnel started serving url
+func (s *Status) Up(provider, url string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.provider = provider
+	s.url = url
+	s.upSince = time.Now()
+}
+
+// Down records that the tunnel stopped
+func (s *Status) Down() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.url = ""
+	s.upSince = time.Time{}
+}
+
+// Get returns the provider and URL of the tunnel and since when it's been up,
+// which is zero when it's down
+func (s *Status) Get() (provider, url string, upSince time.Time) {
+	if s == nil {
+		return "", "", time.Time{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.provider, s.url, s.upSince
+}
+
+// SetRestart sets how the tunnel is restarted
+func (s *Status) SetRestart(restart func() (string, error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.restart = restart
+}
+
+// Restart stops and starts the tunnel again, returning its new URL
+func (s *Status) Restart() (string, error) {
+	if s == nil {
+		return "", fmt.Errorf("no tunnel is running")
+	}
+	s.mu.Lock()
+	restart := s.restart
+	s.mu.Unlock()
+	if restart == nil {
+		return "", fmt.Errorf("no tunnel is running")
+	}
+	return restart()
+}
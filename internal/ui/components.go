@@ -4,76 +4,134 @@ import (
 	"fmt"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/lukeberry99/whook/internal/expr"
 	"github.com/rivo/tview"
 )
 
 func (ui *UI) initComponents() {
-	ui.serviceModal = tview.NewModal().
-		SetText("Select Service").
-		AddButtons([]string{"All"}).
-		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			if buttonLabel == "All" {
-				ui.store.SetSelectedService("")
-				ui.requestList.SetTitle("Requests [yellow](All)[-]")
-			} else {
-				ui.store.SetSelectedService(buttonLabel)
-				ui.requestList.SetTitle(fmt.Sprintf("Requests [yellow](%s)[-]", buttonLabel))
-			}
-			ui.selectedService = buttonLabel
-			ui.refreshFileList()
-
-			ui.app.SetRoot(ui.mainFlex, true)
-		})
-
-	var buttons []string
-	buttons = append(buttons, "All")
-	if ui.config != nil {
-		for service := range ui.config.Services {
-			buttons = append(buttons, service)
-		}
-	}
-	ui.serviceModal.ClearButtons()
-	ui.serviceModal.AddButtons(buttons)
+	problems := ui.applyTheme()
+	problems = append(problems, ui.buildKeymap()...)
 
 	ui.selectedService = "All"
 	// ui.store.SetSelectedService("")
 
-	ui.requestList = tview.NewList()
-	ui.requestList.
-		SetMainTextStyle(tcell.StyleDefault).
-		SetSelectedStyle(tcell.StyleDefault.Background(tcell.ColorBlue)).
-		SetHighlightFullLine(true).
-		SetSecondaryTextColor(tcell.ColorGray).
-		SetTitle(fmt.Sprintf("Requests [yellow](%s)[-]", ui.selectedService)).
-		SetBorder(true)
+	ui.requestTable = tview.NewTable().
+		SetFixed(1, 0).
+		SetSelectable(true, false).
+		SetSelectedStyle(tcell.StyleDefault.Background(ui.theme.selection)).
+		SetSelectedFunc(func(row, column int) {
+			ui.viewSelected()
+		}).
+		SetSelectionChangedFunc(func(row, column int) {
+			// Moving away from the newest event stops following
+			if ui.follow && row != ui.newestRow() {
+				ui.setFollow(false)
+			}
+		})
+	ui.columns = ui.tableColumns()
+	ui.requestTable.SetContent(&requestContent{ui: ui})
+	ui.requestTable.SetBorder(true)
+	ui.updateListTitle()
 
-	ui.requestDetails = tview.NewTextView()
-	ui.requestDetails.
-		SetDynamicColors(true).
-		SetWrap(true).
-		SetText("Select a request to view details").
-		SetTitle("Request Details").
-		SetBorder(true)
+	ui.initDetails()
 
 	ui.logView = tview.NewTextView()
 	ui.logView.
 		SetTitle("Output").
 		SetBorder(true)
 
+	ui.responseView = tview.NewTextView()
+	ui.responseView.
+		SetDynamicColors(true).
+		SetWrap(true).
+		SetTitle("Response").
+		SetBorder(true)
+
 	ui.statusBar = tview.NewTextView().
-		SetText(" ESC: Quit | j/k/↑/↓: Navigate | TAB: Switch Panel | ENTER: View Log | e: Edit | s: Select Service").
-		SetTextColor(tcell.ColorYellow)
+		SetText(ui.statusText(true)).
+		SetTextColor(ui.theme.accent)
+
+	for _, problem := range problems {
+		ui.appendLog(problem)
+	}
+
+	if ui.config != nil && ui.config.UI.Filter != "" {
+		filter, err := expr.Parse(ui.config.UI.Filter)
+		if err != nil {
+			ui.appendLog(fmt.Sprintf("Ignoring the invalid filter %s: %v", ui.config.UI.Filter, err))
+		} else {
+			ui.filter = filter
+			ui.updateListTitle()
+		}
+	}
+}
+
+// updateListTitle shows the selected service and, when it isn't the default
+// one, the session in the request list's title
+func (ui *UI) updateListTitle() {
+	title := "Requests " + ui.theme.accented(fmt.Sprintf("(%s)", tview.Escape(ui.selectedService)))
+	if session := ui.store.Session(); session != "" {
+		title += fmt.Sprintf(" [green]@%s[-]", session)
+	}
+	if ui.store.ReadOnly() {
+		title += " [red]read-only[-]"
+	}
+	if ui.filter != nil {
+		title += " [fuchsia]filtered by " + tview.Escape(ui.filter.String()) + "[-]"
+	}
+	if ui.follow {
+		title += " [blue]following[-]"
+	}
+	ui.requestTable.SetTitle(title)
 }
 
 func (ui *UI) setupLayout() {
+	width := 20
+	if ui.config != nil {
+		width = ui.config.UI.TableWidth()
+	}
+
 	ui.mainFlex = tview.NewFlex().
 		SetDirection(tview.FlexRow).
 		AddItem(tview.NewFlex().
-			AddItem(ui.requestList, 0, 1, true).
-			AddItem(ui.requestDetails, 0, 4, false), // 20%:80% split
+			AddItem(ui.requestTable, 0, width, true).
+			AddItem(ui.details, 0, 100-width, false), // 20%:80% split by default
 			0, 2, true).
-		AddItem(ui.logView, 0, 1, false).
+		AddItem(tview.NewFlex().
+			AddItem(ui.logView, 0, 1, false).
+			AddItem(ui.responseView, 0, 1, false),
+			0, 1, false).
 		AddItem(ui.statusBar, 1, 0, false)
 
 	ui.app.SetRoot(ui.mainFlex, true).EnableMouse(true)
+	// The screen is only created once the app runs, keep it to set the
+	// clipboard
+	ui.app.SetBeforeDrawFunc(func(screen tcell.Screen) bool {
+		ui.screen = screen
+		return false
+	})
+}
+
+func (ui *UI) showModal(p tview.Primitive) {
+	ui.app.SetRoot(p, true)
+	ui.isModalVisible = true
+}
+
+func (ui *UI) closeModal() {
+	ui.closeDashboard()
+	ui.app.SetRoot(ui.mainFlex, true)
+	ui.isModalVisible = false
+}
+
+// centered wraps p so it's drawn in the middle of the screen at a fixed size
+func centered(p tview.Primitive, width, height int) tview.Primitive {
+	return tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().
+			SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(p, height, 0, true).
+			AddItem(nil, 0, 1, false),
+			width, 0, true).
+		AddItem(nil, 0, 1, false)
 }
@@ -0,0 +1,373 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/lukeberry99/whook/internal/replay"
+	"github.com/lukeberry99/whook/internal/storage"
+	"github.com/rivo/tview"
+)
+
+// detailTab is one view of the details pane, key is the default key of the
+// action switching to it
+type detailTab struct {
+	name  string
+	title string
+	key   rune
+}
+
+var detailTabs = []detailTab{
+	{name: "body", title: "Body", key: '1'},
+	{name: "raw", title: "Raw", key: '2'},
+	{name: "headers", title: "Headers", key: '3'},
+	{name: "query", title: "Query", key: '4'},
+	{name: "verification", title: "Verification", key: '5'},
+	{name: "response", title: "Response", key: '6'},
+	{name: "attempts", title: "Attempts", key: '7'},
+}
+
+func newDetailText() *tview.TextView {
+	return tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(true)
+}
+
+// initDetails builds the details pane, a tab bar over a page per tab. The
+// body tab shows either the highlighted payload or its tree.
+func (ui *UI) initDetails() {
+	ui.requestDetails = newDetailText().
+		SetText("Select a request to view details")
+
+	ui.detailsTree = tview.NewTreeView().
+		SetSelectedFunc(func(node *tview.TreeNode) {
+			node.SetExpanded(!node.IsExpanded())
+		})
+	ui.detailsTree.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		node := ui.detailsTree.GetCurrentNode()
+		if node == nil {
+			return event
+		}
+		switch event.Key() {
+		case tcell.KeyLeft:
+			node.Collapse()
+			return nil
+		case tcell.KeyRight:
+			node.Expand()
+			return nil
+		}
+		return event
+	})
+
+	ui.bodyPages = tview.NewPages().
+		AddPage("text", ui.requestDetails, true, true).
+		AddPage("tree", ui.detailsTree, true, false)
+
+	ui.detailPages = tview.NewPages().
+		AddPage("body", ui.bodyPages, true, true)
+	ui.detailViews = map[string]*tview.TextView{}
+	for _, tab := range detailTabs[1:] {
+		view := newDetailText()
+		ui.detailViews[tab.name] = view
+		ui.detailPages.AddPage(tab.name, view, true, false)
+	}
+
+	ui.detailTabBar = tview.NewTextView().
+		SetDynamicColors(true).
+		SetRegions(true)
+	for _, tab := range detailTabs {
+		fmt.Fprintf(ui.detailTabBar, `["%s"] %s %s [""] `, tab.name, tview.Escape(ui.keyFor("tab_"+tab.name)), tab.title)
+	}
+
+	ui.details = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(ui.detailTabBar, 1, 0, false).
+		AddItem(ui.detailPages, 0, 1, true)
+	ui.details.SetBorder(true)
+
+	ui.clearDetails("Select a request to view details")
+	ui.switchDetailTab("body")
+}
+
+// detailsView returns the view of the details pane that's showing
+func (ui *UI) detailsView() tview.Primitive {
+	if ui.detailTab != "body" {
+		return ui.detailViews[ui.detailTab]
+	}
+	if ui.showTree {
+		return ui.detailsTree
+	}
+	return ui.requestDetails
+}
+
+func (ui *UI) detailsFocused() bool {
+	focus := ui.app.GetFocus()
+	if focus == ui.requestDetails || focus == ui.detailsTree {
+		return true
+	}
+	for _, view := range ui.detailViews {
+		if focus == view {
+			return true
+		}
+	}
+	return false
+}
+
+// switchDetailTab shows a tab of the details pane, keeping the focus on the
+// pane when it had it
+func (ui *UI) switchDetailTab(name string) *tcell.EventKey {
+	focused := ui.detailsFocused()
+
+	ui.detailTab = name
+	ui.detailPages.SwitchToPage(name)
+	ui.detailTabBar.Highlight(name)
+
+	title := "Request Details"
+	if name == "body" && ui.showTree {
+		title += " [gray](ENTER/←/→: Collapse and expand)[-]"
+	}
+	ui.details.SetTitle(title)
+
+	if focused {
+		ui.app.SetFocus(ui.detailsView())
+	}
+
+	return nil
+}
+
+// toggleTree switches the body tab between the highlighted text and the tree
+func (ui *UI) toggleTree() *tcell.EventKey {
+	ui.showTree = !ui.showTree
+	if ui.showTree {
+		ui.bodyPages.SwitchToPage("tree")
+	} else {
+		ui.bodyPages.SwitchToPage("text")
+	}
+
+	return ui.switchDetailTab("body")
+}
+
+// clearDetails shows msg in every tab
+func (ui *UI) clearDetails(msg string) {
+	ui.requestDetails.SetText(tview.Escape(msg))
+	ui.detailsTree.SetRoot(nil)
+	for _, view := range ui.detailViews {
+		view.SetText(tview.Escape(msg))
+	}
+}
+
+// showDetails renders a stored event into every tab
+func (ui *UI) showDetails(item storage.EventListItem, content []byte) {
+	var stored storage.StoredEvent
+	if err := json.Unmarshal(content, &stored); err != nil {
+		ui.clearDetails(fmt.Sprintf("Error parsing event: %v", err))
+		ui.requestDetails.SetText(ui.theme.highlightJSON(string(content)))
+		return
+	}
+
+	body := stored.Event
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, body, "", "  "); err == nil {
+		ui.requestDetails.SetText(ui.theme.highlightJSON(pretty.String()))
+	} else {
+		ui.requestDetails.SetText(ui.theme.highlightJSON(string(body)))
+	}
+
+	root, err := ui.theme.jsonTree(body, 2)
+	if err != nil {
+		root = tview.NewTreeNode(fmt.Sprintf("[red]%s[-]", tview.Escape(err.Error())))
+	}
+	ui.detailsTree.SetRoot(root).SetCurrentNode(root)
+
+	ui.detailViews["raw"].SetText(formatRaw(stored.RawBody))
+	ui.detailViews["headers"].SetText(ui.formatRequest(stored))
+	ui.detailViews["query"].SetText(ui.theme.formatQuery(stored.Query))
+	ui.detailViews["verification"].SetText(ui.formatVerification(item, stored))
+	ui.detailViews["response"].SetText(ui.formatResponse(stored.Response))
+	ui.detailViews["attempts"].SetText(ui.formatAttempts(item))
+
+	ui.requestDetails.ScrollToBeginning()
+	for _, view := range ui.detailViews {
+		view.ScrollToBeginning()
+	}
+}
+
+// formatRaw shows the bytes whook received, as text when they're printable
+// and as a hex dump when they're binary
+func formatRaw(raw []byte) string {
+	if len(raw) == 0 {
+		return "[gray]No raw body was captured for this event[-]"
+	}
+
+	if printable(raw) {
+		return fmt.Sprintf("[gray]%d bytes[-]\n\n%s", len(raw), tview.Escape(string(raw)))
+	}
+	return fmt.Sprintf("[gray]%d bytes of binary, shown as hex[-]\n\n%s", len(raw), tview.Escape(hex.Dump(raw)))
+}
+
+func printable(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t theme) formatHeaders(b *strings.Builder, header http.Header) {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, value := range header[key] {
+			fmt.Fprintf(b, "%s: %s\n", t.field(tview.Escape(key)), tview.Escape(value))
+		}
+	}
+}
+
+// formatRequest shows where and when the event was received, followed by its
+// headers
+func (ui *UI) formatRequest(stored storage.StoredEvent) string {
+	var b strings.Builder
+
+	target := stored.Path
+	if stored.Query != "" {
+		target += "?" + stored.Query
+	}
+	if stored.Method != "" {
+		fmt.Fprintf(&b, "%s %s\n", ui.theme.accented(tview.Escape(stored.Method)), tview.Escape(target))
+	}
+	fmt.Fprintf(&b, "[gray]Received[-] %s\n", stored.ReceivedAt.Format(time.RFC3339))
+	if stored.Imported != nil {
+		fmt.Fprintf(&b, "[gray]Imported[-] from %s (%s) at %s\n",
+			tview.Escape(stored.Imported.Source), stored.Imported.Format, stored.Imported.ImportedAt.Format(time.RFC3339))
+	}
+	b.WriteString("\n")
+
+	if len(stored.Headers) == 0 {
+		b.WriteString("[gray]No headers were captured for this event[-]")
+		return b.String()
+	}
+	ui.theme.formatHeaders(&b, stored.Headers)
+
+	return b.String()
+}
+
+func (t theme) formatQuery(query string) string {
+	if query == "" {
+		return "[gray]No query parameters[-]"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[gray]?%s[-]\n\n", tview.Escape(query))
+
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		fmt.Fprintf(&b, "[red]%s[-]\n", tview.Escape(err.Error()))
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range values[key] {
+			fmt.Fprintf(&b, "%s: %s\n", t.field(tview.Escape(key)), tview.Escape(value))
+		}
+	}
+
+	return b.String()
+}
+
+// formatVerification checks the event's signature against its service's
+// signing configuration
+func (ui *UI) formatVerification(item storage.EventListItem, stored storage.StoredEvent) string {
+	serviceName, service := ui.eventService(item.ServiceName)
+	if service.Signing.Scheme == "" {
+		return fmt.Sprintf("[gray]No signing scheme is configured for %s, set signing in its configuration to verify its events[-]", tview.Escape(serviceName))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[gray]Scheme[-] %s\n\n", tview.Escape(service.Signing.Scheme))
+
+	if len(stored.RawBody) == 0 {
+		b.WriteString("[gray]No raw body was captured, so the parsed payload is verified and may differ from what was signed[-]\n\n")
+	}
+
+	if err := replay.Verify(service, stored.Headers, stored.Body()); err != nil {
+		fmt.Fprintf(&b, "[red]✗ %s[-]", tview.Escape(err.Error()))
+	} else {
+		b.WriteString("[green]✓ Signature is valid[-]")
+	}
+
+	return b.String()
+}
+
+func (ui *UI) formatResponse(response *storage.StoredResponse) string {
+	if response == nil {
+		return "[gray]No response was recorded for this event[-]"
+	}
+
+	colour := ui.theme.good
+	if response.Status >= 400 {
+		colour = ui.theme.bad
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s]%d[-] %s\n", colorTag(colour), response.Status, http.StatusText(response.Status))
+	ui.theme.formatHeaders(&b, response.Headers)
+	if response.Body != "" {
+		b.WriteString("\n")
+		b.WriteString(tview.Escape(response.Body))
+	}
+
+	return b.String()
+}
+
+// formatAttempts lists the recorded deliveries of the event, oldest first
+func (ui *UI) formatAttempts(item storage.EventListItem) string {
+	attempts, err := ui.store.ForEvent(item.Path).ListAttempts(item.Filename)
+	if err != nil {
+		return fmt.Sprintf("[red]%s[-]", tview.Escape(err.Error()))
+	}
+	if len(attempts) == 0 {
+		return "[gray]No forwarding or replay attempts have been recorded[-]"
+	}
+
+	var b strings.Builder
+	for _, attempt := range attempts {
+		fmt.Fprintf(&b, "%s %s %s to %s\n",
+			ui.theme.accented(fmt.Sprintf("#%d", attempt.Number)), attempt.SentAt.Format("02/01/2006 15:04:05"), tview.Escape(attempt.Source), tview.Escape(attempt.Target))
+
+		switch {
+		case attempt.Error != "":
+			fmt.Fprintf(&b, "    [red]%s[-]\n", tview.Escape(attempt.Error))
+		case attempt.Succeeded():
+			fmt.Fprintf(&b, "    [green]%d[-] in %s\n", attempt.StatusCode, attempt.Duration.Round(time.Millisecond))
+		default:
+			fmt.Fprintf(&b, "    [red]%d[-] in %s\n", attempt.StatusCode, attempt.Duration.Round(time.Millisecond))
+		}
+		if attempt.ResponseBody != "" {
+			fmt.Fprintf(&b, "    %s\n", tview.Escape(attempt.ResponseBody))
+		}
+	}
+
+	return b.String()
+}
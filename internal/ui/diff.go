@@ -0,0 +1,146 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/lukeberry99/whook/internal/jsondiff"
+	"github.com/lukeberry99/whook/internal/jsonpath"
+	"github.com/lukeberry99/whook/internal/storage"
+	"github.com/rivo/tview"
+)
+
+// defaultDiffIgnore are left out of comparisons until ui.diff_ignore is set,
+// they differ between every delivery
+var defaultDiffIgnore = []string{"id", "created_at", "updated_at", "occurred_at", "timestamp"}
+
+func (ui *UI) diffIgnore() []string {
+	if ui.config != nil && ui.config.UI.DiffIgnore != nil {
+		return ui.config.UI.DiffIgnore
+	}
+	return defaultDiffIgnore
+}
+
+func (ui *UI) isMarked(path string) bool {
+	for _, item := range ui.marked {
+		if item.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// toggleMark marks or unmarks the selected event for comparing, marking a
+// third event unmarks the first
+func (ui *UI) toggleMark() *tcell.EventKey {
+	item, ok := ui.selectedEvent()
+	if !ok {
+		return nil
+	}
+
+	if ui.isMarked(item.Path) {
+		marked := ui.marked[:0]
+		for _, m := range ui.marked {
+			if m.Path != item.Path {
+				marked = append(marked, m)
+			}
+		}
+		ui.marked = marked
+		ui.appendLog(fmt.Sprintf("Unmarked %s", item.Filename))
+		return nil
+	}
+
+	ui.marked = append(ui.marked, item)
+	if len(ui.marked) > 2 {
+		ui.marked = ui.marked[1:]
+	}
+	if len(ui.marked) == 2 {
+		ui.appendLog(fmt.Sprintf("Marked %s, press = to compare it with %s", item.Filename, ui.marked[0].Filename))
+	} else {
+		ui.appendLog(fmt.Sprintf("Marked %s, mark another event or select one and press = to compare", item.Filename))
+	}
+	return nil
+}
+
+// diffEvents returns the two events to compare, the marked ones or the marked
+// one and the selected one
+func (ui *UI) diffEvents() (storage.EventListItem, storage.EventListItem, bool) {
+	switch len(ui.marked) {
+	case 2:
+		return ui.marked[0], ui.marked[1], true
+	case 1:
+		item, ok := ui.selectedEvent()
+		if ok && item.Path != ui.marked[0].Path {
+			return ui.marked[0], item, true
+		}
+	}
+	return storage.EventListItem{}, storage.EventListItem{}, false
+}
+
+// showDiff compares the payloads of two events, listing the paths that were
+// added, removed or changed between them
+func (ui *UI) showDiff() *tcell.EventKey {
+	a, b, ok := ui.diffEvents()
+	if !ok {
+		ui.appendLog("Mark two events with m to compare them")
+		return nil
+	}
+
+	var docs [2]interface{}
+	for i, item := range []storage.EventListItem{a, b} {
+		event, err := storage.LoadEvent(item.Path)
+		if err != nil {
+			ui.appendLog(fmt.Sprintf("Error reading %s: %v", item.Filename, err))
+			return nil
+		}
+		docs[i], err = jsonpath.Decode(event.Event)
+		if err != nil {
+			ui.appendLog(fmt.Sprintf("Error parsing %s: %v", item.Filename, err))
+			return nil
+		}
+	}
+
+	ignore := ui.diffIgnore()
+	changes := jsondiff.Compare(docs[0], docs[1], ignore)
+
+	table := tview.NewTable().
+		SetFixed(1, 0).
+		SetSelectable(true, false)
+
+	title := fmt.Sprintf("%s vs %s", a.Filename, b.Filename)
+	if len(ignore) > 0 {
+		title += " [gray](ignoring " + strings.Join(ignore, ", ") + ")[-]"
+	}
+	table.SetTitle(tview.Escape(title)).SetBorder(true)
+
+	for col, header := range []string{"", "Path", a.Filename, b.Filename} {
+		table.SetCell(0, col, tview.NewTableCell(tview.Escape(header)).
+			SetTextColor(ui.theme.accent).
+			SetSelectable(false))
+	}
+
+	if len(changes) == 0 {
+		table.SetCell(1, 1, tview.NewTableCell("No differences").SetTextColor(ui.theme.muted))
+	}
+	for i, change := range changes {
+		row := i + 1
+
+		mark, color := "~", ui.theme.accent
+		oldText, newText := jsondiff.Format(change.Old), jsondiff.Format(change.New)
+		switch change.Kind {
+		case jsondiff.Added:
+			mark, color, oldText = "+", ui.theme.good, ""
+		case jsondiff.Removed:
+			mark, color, newText = "-", ui.theme.bad, ""
+		}
+
+		table.SetCell(row, 0, tview.NewTableCell(mark).SetTextColor(color))
+		table.SetCell(row, 1, tview.NewTableCell(tview.Escape(change.Path)).SetTextColor(color))
+		table.SetCell(row, 2, tview.NewTableCell(tview.Escape(oldText)).SetMaxWidth(60).SetExpansion(1))
+		table.SetCell(row, 3, tview.NewTableCell(tview.Escape(newText)).SetMaxWidth(60).SetExpansion(1))
+	}
+
+	ui.showModal(centered(table, 120, 30))
+	return nil
+}
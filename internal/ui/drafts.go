@@ -0,0 +1,203 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/lukeberry99/whook/internal/replay"
+	"github.com/lukeberry99/whook/internal/storage"
+	"github.com/rivo/tview"
+)
+
+type sendTarget struct {
+	Name string
+	URL  string
+}
+
+// createDraft copies the selected event into a draft, opens it in $EDITOR and
+// then asks where to send it
+func (ui *UI) createDraft() *tcell.EventKey {
+	if !ui.writable("Drafting") {
+		return nil
+	}
+
+	item, ok := ui.selectedEvent()
+	if !ok {
+		return nil
+	}
+
+	serviceName, service := ui.eventService(item.ServiceName)
+	path, err := ui.store.CreateDraft(item, service.Signing.Header)
+	if err != nil {
+		ui.appendLog(fmt.Sprintf("Error creating draft: %v", err))
+		return nil
+	}
+
+	ui.editFile(path)
+
+	ui.pickTarget(storage.DraftListItem{
+		Name:    filepath.Base(path),
+		Path:    path,
+		Service: serviceName,
+	})
+
+	return nil
+}
+
+func (ui *UI) showDrafts() *tcell.EventKey {
+	drafts, err := ui.store.ListDrafts()
+	if err != nil {
+		ui.appendLog(fmt.Sprintf("Error listing drafts: %v", err))
+		return nil
+	}
+	if len(drafts) == 0 {
+		ui.appendLog("No saved drafts, press d on an event to create one")
+		return nil
+	}
+
+	list := tview.NewList().ShowSecondaryText(true)
+	list.SetTitle("Drafts (e: Edit)").SetBorder(true)
+	for _, draft := range drafts {
+		draft := draft
+		secondary := draft.SavedAt.Format("02/01/2006 15:04:05")
+		if draft.Service != "" {
+			secondary = fmt.Sprintf("%s | Service: %s", secondary, draft.Service)
+		}
+		list.AddItem(draft.Name, secondary, 0, func() {
+			ui.pickTarget(draft)
+		})
+	}
+
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Rune() == 'e' {
+			ui.editFile(drafts[list.GetCurrentItem()].Path)
+			return nil
+		}
+		return event
+	})
+
+	ui.showModal(centered(list, 80, 20))
+	return nil
+}
+
+func (ui *UI) sendTargets(serviceName string) []sendTarget {
+	var targets []sendTarget
+	if ui.config == nil {
+		return targets
+	}
+
+	if service, ok := ui.config.Services[serviceName]; ok && service.ReplayURL != "" {
+		targets = append(targets, sendTarget{Name: serviceName + " replay_url", URL: service.ReplayURL})
+	}
+
+	names := make([]string, 0, len(ui.config.Targets))
+	for name := range ui.config.Targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		targets = append(targets, sendTarget{Name: name, URL: ui.config.Targets[name]})
+	}
+
+	return targets
+}
+
+func (ui *UI) pickTarget(draft storage.DraftListItem) {
+	targets := ui.sendTargets(draft.Service)
+	if len(targets) == 0 {
+		ui.closeModal()
+		ui.appendLog(fmt.Sprintf("Draft saved to %s, configure targets or a replay_url to send it", draft.Path))
+		return
+	}
+
+	list := tview.NewList()
+	list.SetTitle(fmt.Sprintf("Send %s to", draft.Name)).SetBorder(true)
+	for _, target := range targets {
+		target := target
+		list.AddItem(target.Name, target.URL, 0, func() {
+			ui.closeModal()
+			ui.sendDraft(draft, target)
+		})
+	}
+
+	ui.showModal(centered(list, 80, 15))
+}
+
+func (ui *UI) sendDraft(item storage.DraftListItem, target sendTarget) {
+	draft, err := ui.store.ReadDraft(item)
+	if err != nil {
+		ui.appendLog(fmt.Sprintf("Error reading draft: %v", err))
+		return
+	}
+
+	_, service := ui.eventService(draft.Service)
+	req, err := replay.ForService(service)
+	if err != nil {
+		ui.appendLog(fmt.Sprintf("Error configuring %s: %v", draft.Service, err))
+		return
+	}
+	req.Target = target.URL
+	req.Method = draft.Method
+	req.Headers = draft.Headers
+	req.Body = draft.Body
+
+	ui.appendLog(fmt.Sprintf("Sending draft %s to %s", item.Name, target.URL))
+	ui.responseView.SetText("Waiting for response...")
+	store := ui.store
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		sentAt := time.Now()
+		result, err := replay.Send(ctx, nil, req)
+		var recordErr error
+		// Drafts written by hand don't belong to an event
+		if event := storage.DraftEvent(item.Name); event != "" {
+			recordErr = recordAttempt(store, event, "draft", target.URL, sentAt, result, err)
+		}
+
+		ui.app.QueueUpdateDraw(func() {
+			if recordErr != nil {
+				ui.appendLog(fmt.Sprintf("Error recording the send of draft %s: %v", item.Name, recordErr))
+			}
+			if err != nil {
+				ui.responseView.SetText(fmt.Sprintf("[red]%v[-]", err))
+				ui.appendLog(fmt.Sprintf("Sending draft %s failed: %v", item.Name, err))
+				return
+			}
+			ui.responseView.SetText(ui.formatResult(result))
+			ui.responseView.ScrollToBeginning()
+			ui.appendLog(fmt.Sprintf("Draft %s returned %d", item.Name, result.StatusCode))
+		})
+	}()
+}
+
+func (ui *UI) formatResult(result *replay.Result) string {
+	colour := ui.theme.good
+	if result.StatusCode >= 400 {
+		colour = ui.theme.bad
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s]%d[-] in %s\n", colorTag(colour), result.StatusCode, result.Duration.Round(time.Millisecond))
+
+	keys := make([]string, 0, len(result.Headers))
+	for key := range result.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s: %s\n", ui.theme.field(tview.Escape(key)), tview.Escape(strings.Join(result.Headers[key], ", ")))
+	}
+
+	b.WriteString("\n")
+	b.WriteString(tview.Escape(string(result.Body)))
+
+	return b.String()
+}
@@ -1,102 +1,88 @@
 package ui
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 
 	"github.com/gdamore/tcell/v2"
 )
 
+// setupKeyBindings runs the action bound to each key pressed outside of
+// modals, see keys.go for the actions
 func (ui *UI) setupKeyBindings() {
 	ui.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		switch event.Key() {
-		case tcell.KeyEsc:
-			if ui.isModalVisible {
-				ui.app.SetRoot(ui.mainFlex, true)
-				ui.isModalVisible = false
+		// Modals handle their own keys, ESC always closes them
+		if ui.isModalVisible {
+			switch event.Key() {
+			case tcell.KeyEsc:
+				ui.closeModal()
+				return nil
+			case tcell.KeyTab:
 				return nil
 			}
-			ui.app.Stop()
-		case tcell.KeyTab:
-			return ui.handleTabKey()
-		}
-
-		if event.Rune() == 'e' {
-			return ui.openInEditor()
+			return event
 		}
 
-		if event.Rune() == 's' {
-			ui.app.SetRoot(ui.serviceModal, true)
-			ui.isModalVisible = true
-			return nil
+		a, ok := ui.keymap[keyName(event)]
+		if !ok || (a.listOnly && ui.app.GetFocus() != ui.requestTable) {
+			return event
 		}
-
-		if ui.app.GetFocus() == ui.requestList {
-			return ui.handleListNavigation(event)
-		}
-
-		return event
+		return a.run(ui)
 	})
 }
 
 func (ui *UI) openInEditor() *tcell.EventKey {
-	currentIndex := ui.requestList.GetCurrentItem()
-	if currentIndex < 0 {
+	if !ui.writable("Editing") {
 		return nil
 	}
 
-	filename, _ := ui.requestList.GetItemText(currentIndex)
-	if filename == "" {
+	item, ok := ui.selectedEvent()
+	if !ok {
 		return nil
 	}
 
-	fullPath := ui.store.GetFullPath(filename)
+	ui.editFile(item.Path)
+
+	return nil
+}
+
+// writable reports whether actions that change the storage can run, logging
+// why not when it's read-only
+func (ui *UI) writable(action string) bool {
+	if !ui.store.ReadOnly() {
+		return true
+	}
+
+	ui.appendLog(fmt.Sprintf("%s is disabled while browsing read-only storage", action))
+	return false
+}
 
+// editFile suspends the UI and opens path in $EDITOR until the editor exits
+func (ui *UI) editFile(path string) {
 	editor := os.Getenv("EDITOR")
 	if editor == "" {
 		editor = "vim"
 	}
 
 	ui.app.Suspend(func() {
-		cmd := exec.Command(editor, fullPath)
+		cmd := exec.Command(editor, path)
 		cmd.Stdin = os.Stdin
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 		_ = cmd.Run()
 	})
-
-	return nil
 }
 
 func (ui *UI) handleTabKey() *tcell.EventKey {
-	switch ui.app.GetFocus() {
-	case ui.requestList:
-		ui.app.SetFocus(ui.requestDetails)
-		ui.statusBar.SetText(" ESC: Quit | j/k/↑/↓: Navigate | TAB: Switch Panel | e: Edit | s: Select Service")
-	case ui.requestDetails:
-		ui.app.SetFocus(ui.requestList)
-		ui.statusBar.SetText(" ESC: Quit | j/k/↑/↓: Navigate | TAB: Switch Panel | ENTER: View Log | e: Edit | s: Select Service")
+	switch {
+	case ui.app.GetFocus() == ui.requestTable:
+		ui.app.SetFocus(ui.detailsView())
+		ui.statusBar.SetText(ui.statusText(false))
+	case ui.detailsFocused():
+		ui.app.SetFocus(ui.requestTable)
+		ui.statusBar.SetText(ui.statusText(true))
 	}
 
 	return nil
 }
-
-func (ui *UI) handleListNavigation(event *tcell.EventKey) *tcell.EventKey {
-	switch {
-	case event.Rune() == 'j' || event.Key() == tcell.KeyDown:
-		current := ui.requestList.GetCurrentItem()
-		if current < ui.requestList.GetItemCount()-1 {
-			ui.requestList.SetCurrentItem(current + 1)
-		}
-		return nil
-	case event.Rune() == 'k' || event.Key() == tcell.KeyUp:
-		current := ui.requestList.GetCurrentItem()
-		if current > 0 {
-			ui.requestList.SetCurrentItem(current - 1)
-		}
-		return nil
-	case event.Rune() == 'e':
-		return ui.openInEditor()
-	}
-	return event
-}
@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/lukeberry99/whook/internal/export"
+	"github.com/lukeberry99/whook/internal/query"
+	"github.com/lukeberry99/whook/internal/storage"
+	"github.com/rivo/tview"
+)
+
+// showExport asks for a format and exports the events currently listed into
+// the working directory
+func (ui *UI) showExport() *tcell.EventKey {
+	if len(ui.rows) == 0 {
+		ui.appendLog("No events to export")
+		return nil
+	}
+
+	list := tview.NewList()
+	list.SetTitle(fmt.Sprintf("Export %d events", len(ui.rows))).SetBorder(true)
+
+	for _, format := range export.Formats() {
+		format := format
+		list.AddItem(format, "", 0, func() {
+			ui.closeModal()
+			ui.exportEvents(format, ui.listedEvents())
+		})
+	}
+
+	ui.showModal(centered(list, 40, len(export.Formats())*2+2))
+	return nil
+}
+
+// exportEvents writes items to a file in the working directory
+func (ui *UI) exportEvents(format string, items []storage.EventListItem) {
+	matches, err := query.Load(ui.store, ui.config, items)
+	if err != nil {
+		ui.appendLog(fmt.Sprintf("Error loading events: %v", err))
+		return
+	}
+
+	path := fmt.Sprintf("whook-export-%s%s", time.Now().Format("20060102-150405"), export.Extension(format))
+	file, err := os.Create(path)
+	if err != nil {
+		ui.appendLog(fmt.Sprintf("Error creating %s: %v", path, err))
+		return
+	}
+	defer file.Close()
+
+	opts := export.Options{
+		BaseURL:          fmt.Sprintf("http://localhost:%d", ui.config.Server.Port),
+		SignatureHeaders: ui.config.SignatureHeaders(),
+	}
+	if ui.selectedService != "All" {
+		opts.Name = "whook " + ui.selectedService
+	}
+	if err := export.Write(file, format, matches, opts); err != nil {
+		ui.appendLog(fmt.Sprintf("Error exporting events: %v", err))
+		return
+	}
+
+	ui.appendLog(fmt.Sprintf("Exported %d events to %s", len(matches), path))
+}
+
+// exportMarked exports the marked events, or the selected one when none are
+// marked
+func (ui *UI) exportMarked(format string) {
+	items := ui.marked
+	if len(items) == 0 {
+		item, ok := ui.selectedEvent()
+		if !ok {
+			ui.appendLog("Mark events with m to export them")
+			return
+		}
+		items = []storage.EventListItem{item}
+	}
+	ui.exportEvents(format, items)
+}
+
+// copyAsCurl copies a curl command sending the selected event to the
+// clipboard and shows it in the response pane, for terminals that don't
+// support setting the clipboard
+func (ui *UI) copyAsCurl() {
+	item, ok := ui.selectedEvent()
+	if !ok {
+		return
+	}
+
+	matches, err := query.Load(ui.store, ui.config, []storage.EventListItem{item})
+	if err != nil || len(matches) == 0 {
+		ui.appendLog(fmt.Sprintf("Error loading %s: %v", item.Filename, err))
+		return
+	}
+
+	var b bytes.Buffer
+	opts := export.Options{
+		BaseURL:          fmt.Sprintf("http://localhost:%d", ui.config.Server.Port),
+		SignatureHeaders: ui.config.SignatureHeaders(),
+	}
+	if err := export.Write(&b, export.FormatCurl, matches, opts); err != nil {
+		ui.appendLog(fmt.Sprintf("Error writing curl command: %v", err))
+		return
+	}
+
+	if ui.screen != nil {
+		ui.screen.SetClipboard(b.Bytes())
+	}
+	ui.responseView.SetText(tview.Escape(b.String()))
+	ui.responseView.ScrollToBeginning()
+	ui.appendLog(fmt.Sprintf("Copied %s as a curl command", item.Filename))
+}
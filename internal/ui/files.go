@@ -3,6 +3,7 @@ package ui
 import (
 	"fmt"
 	"log"
+	"os"
 	"strings"
 
 	"github.com/lukeberry99/whook/internal/storage"
@@ -15,56 +16,49 @@ func (ui *UI) loadInitialFiles() {
 func (ui *UI) watchFileUpdates() {
 	go func() {
 		updates := ui.store.WatchEvents()
-		for range updates {
+		for changes := range updates {
 			ui.app.QueueUpdateDraw(func() {
-				ui.refreshFileList()
+				ui.applyChanges(changes)
+				ui.reloadDashboard(changes.All || changes.Attempts)
 			})
 		}
 	}()
 }
 
-func (ui *UI) addFileToList(file storage.EventListItem) {
-	secondaryText := file.ReceivedAt
-	if file.ServiceName != "" && ui.selectedService == "All" {
-		secondaryText = fmt.Sprintf("%s | Service: %s", file.ReceivedAt, file.ServiceName)
+// viewSelected shows the selected event in the details pane
+func (ui *UI) viewSelected() {
+	item, ok := ui.selectedEvent()
+	if !ok {
+		return
 	}
 
-	ui.requestList.AddItem(file.Filename, secondaryText, 0, func() {
-		content, err := ui.store.ReadEvent(file.Filename)
-		if err != nil {
-			ui.requestDetails.SetText(fmt.Sprintf("Error reading file: %v", err))
-			return
-		}
+	// The item's path finds events in service directories when every
+	// service is listed
+	content, err := os.ReadFile(item.Path)
+	if err != nil {
+		ui.clearDetails(fmt.Sprintf("Error reading file: %v", err))
+		return
+	}
 
-		// Add syntax highlighting for JSON keys
-		coloredContent := colorJSONKeys(string(content))
-		ui.requestDetails.SetText(coloredContent)
-	})
+	ui.showDetails(item, content)
+	ui.read[item.Path] = true
 }
 
-// This is a poor implementation, it would be better to support
-// something like treesitter and actual colorschemes
-func colorJSONKeys(content string) string {
-	lines := strings.Split(content, "\n")
-	for i, line := range lines {
-		if strings.Contains(line, "\"") {
-			// Find the position of the colon to identify keys
-			colonPos := strings.Index(line, ":")
-			if colonPos > 0 {
-				// Find the key portion (everything before the colon)
-				beforeColon := line[:colonPos]
-				afterColon := line[colonPos:]
-
-				// Add color to the entire key including quotes
-				if strings.Contains(beforeColon, "\"") {
-					beforeColon = "[#00ffff]" + beforeColon + "[-:-:-]"
-				}
-
-				lines[i] = beforeColon + afterColon
-			}
-		}
+func (ui *UI) selectedEvent() (storage.EventListItem, bool) {
+	row, _ := ui.requestTable.GetSelection()
+	// The first row is the header
+	currentIndex := row - 1
+	if currentIndex < 0 || currentIndex >= len(ui.rows) {
+		return storage.EventListItem{}, false
 	}
-	return strings.Join(lines, "\n")
+	return ui.rows[currentIndex].item, true
+}
+
+// appendLog writes to the output pane, it must be called from the UI goroutine
+func (ui *UI) appendLog(msg string) {
+	currentText := ui.logView.GetText(true)
+	ui.logView.SetText(currentText + msg + "\n")
+	ui.logView.ScrollToEnd()
 }
 
 func (ui *UI) watchLogs(logChan <-chan string) {
@@ -72,9 +66,7 @@ func (ui *UI) watchLogs(logChan <-chan string) {
 		for logMsg := range logChan {
 			ui.app.QueueUpdateDraw(func() {
 				if ui.selectedService == "All" || strings.Contains(logMsg, ui.selectedService) {
-					currentText := ui.logView.GetText(true)
-					ui.logView.SetText(currentText + logMsg + "\n")
-					ui.logView.ScrollToEnd()
+					ui.appendLog(logMsg)
 				}
 			})
 		}
@@ -82,13 +74,13 @@ func (ui *UI) watchLogs(logChan <-chan string) {
 }
 
 func (ui *UI) refreshFileList() {
-	ui.requestList.Clear()
 	files, err := ui.store.ListEvents()
 	if err != nil {
 		log.Fatalf("failed to load files: %v", err)
 	}
 
-	for _, file := range files {
-		ui.addFileToList(file)
-	}
+	ui.keepSelection(func() {
+		ui.updateRows(files)
+		ui.sortRows(ui.rows)
+	})
 }
@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/lukeberry99/whook/internal/generate"
+	"github.com/lukeberry99/whook/internal/replay"
+	"github.com/rivo/tview"
+)
+
+// generateServices returns the services templates are offered for, the
+// selected one or every configured service plus the built-in chargebee set
+func (ui *UI) generateServices() []string {
+	if ui.selectedService != "All" {
+		return []string{ui.selectedService}
+	}
+
+	services := map[string]bool{"chargebee": true}
+	if ui.config != nil {
+		for name := range ui.config.Services {
+			services[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (ui *UI) showTemplates() *tcell.EventKey {
+	list := tview.NewList()
+	list.SetTitle("Generate Event").SetBorder(true)
+
+	for _, serviceName := range ui.generateServices() {
+		_, service := ui.eventService(serviceName)
+		templates, err := generate.List(serviceName, generate.Dir(serviceName, service))
+		if err != nil {
+			ui.appendLog(fmt.Sprintf("Error listing templates for %s: %v", serviceName, err))
+			continue
+		}
+
+		for _, t := range templates {
+			t := t
+			list.AddItem(t.Name, fmt.Sprintf("Service: %s | %s", t.Service, t.Source), 0, func() {
+				ui.pickGenerateDestination(t)
+			})
+		}
+	}
+
+	if list.GetItemCount() == 0 {
+		ui.appendLog("No templates found")
+		return nil
+	}
+
+	ui.showModal(centered(list, 80, 20))
+	return nil
+}
+
+func (ui *UI) pickGenerateDestination(t generate.Template) {
+	list := tview.NewList()
+	list.SetTitle(fmt.Sprintf("Generate %s into", t.Name)).SetBorder(true)
+
+	// Read-only storage can still send generated events to a target
+	if !ui.store.ReadOnly() {
+		list.AddItem("Store in whook", fmt.Sprintf("Saved under %s", t.Service), 0, func() {
+			ui.closeModal()
+			ui.storeGenerated(t)
+		})
+	}
+	for _, target := range ui.sendTargets(t.Service) {
+		target := target
+		list.AddItem(target.Name, target.URL, 0, func() {
+			ui.closeModal()
+			ui.deliverGenerated(t, target)
+		})
+	}
+
+	if list.GetItemCount() == 0 {
+		ui.closeModal()
+		ui.appendLog(fmt.Sprintf("No targets to send %s to, storing it is disabled while browsing read-only storage", t.Name))
+		return
+	}
+
+	ui.showModal(centered(list, 80, 15))
+}
+
+func (ui *UI) storeGenerated(t generate.Template) {
+	body, err := t.Render(nil)
+	if err != nil {
+		ui.appendLog(fmt.Sprintf("Error generating %s: %v", t.Name, err))
+		return
+	}
+
+	filename, err := ui.store.StoreBody(t.Service, time.Now(), body)
+	if err != nil {
+		ui.appendLog(fmt.Sprintf("Error storing generated %s: %v", t.Name, err))
+		return
+	}
+
+	ui.appendLog(fmt.Sprintf("Generated %s: %s", t.Name, filename))
+}
+
+func (ui *UI) deliverGenerated(t generate.Template, target sendTarget) {
+	body, err := t.Render(nil)
+	if err != nil {
+		ui.appendLog(fmt.Sprintf("Error generating %s: %v", t.Name, err))
+		return
+	}
+
+	_, service := ui.eventService(t.Service)
+	req, err := replay.ForService(service)
+	if err != nil {
+		ui.appendLog(fmt.Sprintf("Error configuring %s: %v", t.Service, err))
+		return
+	}
+	req.Target = target.URL
+	req.Body = body
+
+	ui.appendLog(fmt.Sprintf("Sending generated %s to %s", t.Name, target.URL))
+	ui.responseView.SetText("Waiting for response...")
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		result, err := replay.Send(ctx, nil, req)
+
+		ui.app.QueueUpdateDraw(func() {
+			if err != nil {
+				ui.responseView.SetText(fmt.Sprintf("[red]%v[-]", err))
+				ui.appendLog(fmt.Sprintf("Sending generated %s failed: %v", t.Name, err))
+				return
+			}
+			ui.responseView.SetText(ui.formatResult(result))
+			ui.responseView.ScrollToBeginning()
+			ui.appendLog(fmt.Sprintf("Generated %s returned %d", t.Name, result.StatusCode))
+		})
+	}()
+}
@@ -0,0 +1,181 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// highlightJSON colours the keys, strings, numbers, booleans and nulls of a
+// JSON document with tview colour tags. Anything it doesn't recognise, such
+// as a body that isn't JSON, is passed through escaped.
+func (t theme) highlightJSON(content string) string {
+	var b strings.Builder
+	plainStart := 0
+
+	flush := func(end int) {
+		b.WriteString(tview.Escape(content[plainStart:end]))
+	}
+	colour := func(start, end int, color string) {
+		flush(start)
+		fmt.Fprintf(&b, "[%s]%s[-]", color, tview.Escape(content[start:end]))
+		plainStart = end
+	}
+
+	for i := 0; i < len(content); {
+		c := content[i]
+		// Numbers and literals only start a token, not in the middle of a word
+		word := i > 0 && isWordByte(content[i-1])
+		switch {
+		case c == '"':
+			end := stringEnd(content, i)
+			color := t.json.str
+			if isKey(content, end) {
+				color = t.json.key
+			}
+			colour(i, end, color)
+			i = end
+		case !word && (c == '-' || isDigit(c)):
+			end := i + 1
+			for end < len(content) && strings.IndexByte("0123456789+-.eE", content[end]) >= 0 {
+				end++
+			}
+			colour(i, end, t.json.number)
+			i = end
+		case !word && strings.HasPrefix(content[i:], "true"):
+			colour(i, i+4, t.json.boolean)
+			i += 4
+		case !word && strings.HasPrefix(content[i:], "false"):
+			colour(i, i+5, t.json.boolean)
+			i += 5
+		case !word && strings.HasPrefix(content[i:], "null"):
+			colour(i, i+4, t.json.null)
+			i += 4
+		default:
+			i++
+		}
+	}
+	flush(len(content))
+
+	return b.String()
+}
+
+// stringEnd returns the index just past the string starting at start,
+// skipping escaped quotes. An unterminated string runs to the end of content.
+func stringEnd(content string, start int) int {
+	for i := start + 1; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(content)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isWordByte(c byte) bool {
+	return isDigit(c) || c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isKey reports whether the string ending at end is an object key, that is
+// whether a colon follows it
+func isKey(content string, end int) bool {
+	rest := strings.TrimLeft(content[end:], " \t\r\n")
+	return strings.HasPrefix(rest, ":")
+}
+
+// jsonTree builds a tree of a JSON document that keeps the order of its keys.
+// Objects and arrays nested deeper than expandDepth start collapsed.
+func (t theme) jsonTree(data []byte, expandDepth int) (*tview.TreeNode, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	root, err := t.jsonNode(dec, "", 0, expandDepth)
+	if err != nil {
+		return nil, fmt.Errorf("parsing json: %w", err)
+	}
+
+	return root, nil
+}
+
+func (t theme) jsonNode(dec *json.Decoder, label string, depth, expandDepth int) (*tview.TreeNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := ""
+	if label != "" {
+		prefix = fmt.Sprintf("[%s]%s[-]: ", t.json.key, tview.Escape(label))
+	}
+
+	switch v := tok.(type) {
+	case json.Delim:
+		node := tview.NewTreeNode("").
+			SetSelectable(true).
+			SetExpanded(depth < expandDepth)
+
+		count := 0
+		for dec.More() {
+			childLabel := fmt.Sprintf("[%d]", count)
+			if v == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				childLabel = fmt.Sprint(key)
+			}
+
+			child, err := t.jsonNode(dec, childLabel, depth+1, expandDepth)
+			if err != nil {
+				return nil, err
+			}
+			node.AddChild(child)
+			count++
+		}
+		// Consume the closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+
+		summary := fmt.Sprintf("{%s}", plural(count, "key"))
+		if v == '[' {
+			summary = tview.Escape(fmt.Sprintf("[%s]", plural(count, "item")))
+		}
+		node.SetText(fmt.Sprintf("%s[%s]%s[-]", prefix, t.json.null, summary))
+
+		return node, nil
+	case string:
+		return tview.NewTreeNode(fmt.Sprintf("%s[%s]%s[-]", prefix, t.json.str, tview.Escape(quoteJSON(v)))), nil
+	case json.Number:
+		return tview.NewTreeNode(fmt.Sprintf("%s[%s]%s[-]", prefix, t.json.number, v)), nil
+	case bool:
+		return tview.NewTreeNode(fmt.Sprintf("%s[%s]%t[-]", prefix, t.json.boolean, v)), nil
+	default:
+		return tview.NewTreeNode(fmt.Sprintf("%s[%s]null[-]", prefix, t.json.null)), nil
+	}
+}
+
+func plural(count int, noun string) string {
+	if count == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// quoteJSON quotes s as it appears in JSON, without escaping HTML characters
+func quoteJSON(s string) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,264 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// action is something the UI does when its key is pressed. Keys are bound
+// to actions by name, so they can be changed in ui.keys of the config.
+type action struct {
+	name  string
+	title string
+	key   string
+	// listOnly actions only work while the request list is focused
+	listOnly bool
+	// status actions are shown in the status bar, every action is in the help
+	status bool
+	// writes actions change the storage, so they're hidden when it's read-only
+	writes bool
+	// shown hides the action when it returns false
+	shown func(ui *UI) bool
+	run   func(ui *UI) *tcell.EventKey
+}
+
+func arrow(key tcell.Key) func(ui *UI) *tcell.EventKey {
+	return func(ui *UI) *tcell.EventKey {
+		return tcell.NewEventKey(key, 0, tcell.ModNone)
+	}
+}
+
+var actions = []action{
+	{name: "quit", title: "Quit", key: "esc", status: true, run: (*UI).quit},
+	{name: "switch_panel", title: "Switch Panel", key: "tab", status: true, run: (*UI).handleTabKey},
+	{name: "up", title: "Up", key: "k", status: true, run: arrow(tcell.KeyUp)},
+	{name: "down", title: "Down", key: "j", status: true, run: arrow(tcell.KeyDown)},
+	{name: "view", title: "View", key: "enter", listOnly: true, status: true, run: func(ui *UI) *tcell.EventKey {
+		ui.viewSelected()
+		return nil
+	}},
+	{name: "edit", title: "Edit", key: "e", status: true, writes: true, run: (*UI).openInEditor},
+	{name: "replay", title: "Replay", key: "r", status: true, run: (*UI).replaySelected},
+	{name: "transform", title: "Transform", key: "t", status: true, run: (*UI).previewTransform},
+	{name: "draft", title: "Draft", key: "d", status: true, writes: true, run: (*UI).createDraft},
+	{name: "drafts", title: "Drafts", key: "D", status: true, run: (*UI).showDrafts},
+	{name: "generate", title: "Generate", key: "g", status: true, run: (*UI).showTemplates},
+	{name: "export", title: "Export", key: "x", status: true, run: (*UI).showExport},
+	{name: "tree", title: "Tree View", key: "v", status: true, run: (*UI).toggleTree},
+	{name: "query", title: "Query", key: "/", status: true, run: (*UI).showQuery},
+	{name: "stats", title: "Statistics", key: "#", status: true, run: (*UI).showDashboard},
+	{name: "sort", title: "Sort", key: "o", listOnly: true, status: true, run: (*UI).cycleSort},
+	{name: "reverse_sort", title: "Reverse Sort", key: "O", listOnly: true, run: (*UI).reverseSort},
+	{name: "columns", title: "Columns", key: "C", listOnly: true, status: true, run: (*UI).showColumns},
+	{name: "follow", title: "Follow Newest", key: "F", listOnly: true, status: true, run: (*UI).toggleFollow},
+	{name: "mark", title: "Mark", key: "m", listOnly: true, status: true, run: (*UI).toggleMark},
+	{name: "diff", title: "Compare Marked", key: "=", listOnly: true, status: true, run: (*UI).showDiff},
+	{
+		name:   "scheduler",
+		title:  "Scheduler",
+		key:    "S",
+		status: true,
+		shown:  func(ui *UI) bool { return ui.scheduler != nil },
+		run:    (*UI).showScheduler,
+	},
+	{name: "sessions", title: "Sessions", key: "N", status: true, run: (*UI).showSessions},
+	{name: "services", title: "Select Service", key: "s", status: true, run: (*UI).showServices},
+	{name: "palette", title: "Commands", key: ":", status: true, run: (*UI).showPalette},
+	{name: "help", title: "Help", key: "?", status: true, run: (*UI).showHelp},
+}
+
+// allActions returns the actions with one switching to each detail tab
+func allActions() []action {
+	all := append([]action{}, actions...)
+	for _, tab := range detailTabs {
+		name := tab.name
+		all = append(all, action{
+			name:  "tab_" + name,
+			title: tab.title + " Tab",
+			key:   string(tab.key),
+			run:   func(ui *UI) *tcell.EventKey { return ui.switchDetailTab(name) },
+		})
+	}
+	return all
+}
+
+// keyName names the key of event the way keys are written in the config:
+// the character for printable keys, space, or tcell's lowercased name such
+// as esc, enter, ctrl-q or f1
+func keyName(event *tcell.EventKey) string {
+	if event.Key() != tcell.KeyRune {
+		if name, ok := tcell.KeyNames[event.Key()]; ok {
+			return strings.ToLower(name)
+		}
+		return ""
+	}
+
+	name := string(event.Rune())
+	if event.Rune() == ' ' {
+		name = "space"
+	}
+	if event.Modifiers()&tcell.ModAlt != 0 {
+		name = "alt-" + name
+	}
+	return name
+}
+
+// normalizeKey lowercases named keys, characters keep their case
+func normalizeKey(key string) string {
+	key = strings.TrimSpace(key)
+	if len([]rune(key)) == 1 {
+		return key
+	}
+	return strings.ReplaceAll(strings.ToLower(key), "+", "-")
+}
+
+// displayKey is how a key is shown in the status bar and help
+func displayKey(key string) string {
+	if len([]rune(key)) == 1 {
+		return key
+	}
+	return strings.ToUpper(key)
+}
+
+// buildKeymap binds the actions to their keys, taking any from the config.
+// Configured keys are bound first, so a default key that's configured for
+// another action is left unbound. It returns problems with the configured
+// keys, the defaults are kept for those.
+func (ui *UI) buildKeymap() []string {
+	var configured map[string]string
+	if ui.config != nil {
+		configured = ui.config.UI.Keys
+	}
+
+	var problems []string
+	all := allActions()
+	known := map[string]bool{}
+	for _, a := range all {
+		known[a.name] = true
+	}
+	names := make([]string, 0, len(configured))
+	for name := range configured {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !known[name] {
+			problems = append(problems, fmt.Sprintf("Unknown action %s in ui.keys", name))
+		}
+	}
+
+	ui.actions = all
+	ui.keymap = map[string]*action{}
+	var defaults []*action
+	for i := range ui.actions {
+		a := &ui.actions[i]
+		key := normalizeKey(configured[a.name])
+		if key == "" {
+			defaults = append(defaults, a)
+			continue
+		}
+
+		if other, taken := ui.keymap[key]; taken {
+			problems = append(problems, fmt.Sprintf("%s is bound to both %s and %s in ui.keys, it does %s", displayKey(key), other.name, a.name, other.name))
+			defaults = append(defaults, a)
+			continue
+		}
+		a.key = key
+		ui.keymap[key] = a
+	}
+
+	for _, a := range defaults {
+		if _, taken := ui.keymap[a.key]; taken {
+			a.key = ""
+			continue
+		}
+		ui.keymap[a.key] = a
+	}
+
+	return problems
+}
+
+// keyFor returns the key bound to the named action
+func (ui *UI) keyFor(name string) string {
+	for _, a := range ui.actions {
+		if a.name == name {
+			return displayKey(a.key)
+		}
+	}
+	return ""
+}
+
+func (ui *UI) available(a *action) bool {
+	if a.writes && ui.store.ReadOnly() {
+		return false
+	}
+	return a.shown == nil || a.shown(ui)
+}
+
+// statusText lists the keys that work in the current panel, leaving out the
+// ones that change the storage when it's read-only
+func (ui *UI) statusText(listFocused bool) string {
+	var keys []string
+	for i := range ui.actions {
+		a := &ui.actions[i]
+		if !a.status || a.key == "" || !ui.available(a) || (a.listOnly && !listFocused) {
+			continue
+		}
+		keys = append(keys, fmt.Sprintf("%s: %s", displayKey(a.key), a.title))
+	}
+
+	return " " + strings.Join(keys, " | ")
+}
+
+// showHelp lists every action and its key
+func (ui *UI) showHelp() *tcell.EventKey {
+	view := tview.NewTextView().
+		SetDynamicColors(true)
+	view.SetBorder(true).SetTitle("Keys (ESC: Close, set in ui.keys of the config)")
+
+	var b strings.Builder
+	for i := range ui.actions {
+		a := &ui.actions[i]
+		if !ui.available(a) {
+			continue
+		}
+		key := displayKey(a.key)
+		if a.key == "" {
+			key = "unbound"
+		}
+		fmt.Fprintf(&b, "[%s]%8s[-]  %-20s [gray]%s", ui.theme.accentTag(), tview.Escape(key), a.title, a.name)
+		if a.listOnly {
+			b.WriteString(", in the request list")
+		}
+		b.WriteString("[-]\n")
+	}
+	view.SetText(b.String())
+
+	ui.showModal(centered(view, 70, min(len(ui.actions)+2, 40)))
+	return nil
+}
+
+// quit stops the UI, asking first unless ui.confirm_quit is false
+func (ui *UI) quit() *tcell.EventKey {
+	if ui.config != nil && !ui.config.UI.ShouldConfirmQuit() {
+		ui.app.Stop()
+		return nil
+	}
+
+	modal := tview.NewModal().
+		SetText("Quit whook?").
+		AddButtons([]string{"Quit", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			if buttonLabel == "Quit" {
+				ui.app.Stop()
+				return
+			}
+			ui.closeModal()
+		})
+	ui.showModal(modal)
+	return nil
+}
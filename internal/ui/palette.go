@@ -0,0 +1,327 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/lukeberry99/whook/internal/export"
+	"github.com/rivo/tview"
+)
+
+// command is an entry of the command palette. Commands with args take an
+// argument, completed from what args returns. Free commands also take
+// arguments that aren't completions.
+type command struct {
+	name  string
+	title string
+	args  func(ui *UI) []string
+	free  bool
+	run   func(ui *UI, arg string)
+}
+
+// argCommands are the palette's commands that take an argument, the rest
+// are the actions bound to keys
+var argCommands = []command{
+	{
+		name:  "replay_to",
+		title: "Replay the selected event to a target or URL",
+		args:  (*UI).targetNames,
+		free:  true,
+		run:   (*UI).replayTo,
+	},
+	{
+		name:  "export_listed",
+		title: "Export the listed events",
+		args:  func(ui *UI) []string { return export.Formats() },
+		run:   func(ui *UI, format string) { ui.exportEvents(format, ui.listedEvents()) },
+	},
+	{
+		name:  "export_marked",
+		title: "Export the marked events",
+		args:  func(ui *UI) []string { return export.Formats() },
+		run:   (*UI).exportMarked,
+	},
+	{
+		name:  "switch_session",
+		title: "Switch to a session",
+		args:  (*UI).sessionNames,
+		run:   (*UI).switchSessionNamed,
+	},
+	{
+		name:  "select_service",
+		title: "List a service's events",
+		args:  (*UI).serviceNames,
+		run:   (*UI).selectService,
+	},
+	{
+		name:  "tag",
+		title: "Tag the selected event",
+		args:  (*UI).tagNames,
+		free:  true,
+		run:   (*UI).tagSelected,
+	},
+	{
+		name:  "untag",
+		title: "Remove a tag from the selected event",
+		args:  (*UI).selectedTags,
+		run:   (*UI).untagSelected,
+	},
+	{
+		name:  "sort_by",
+		title: "Sort the table by a column",
+		args:  (*UI).columnNames,
+		run: func(ui *UI, name string) {
+			ui.sortColumn = name
+			ui.renderTable()
+		},
+	},
+	{
+		name:  "add_column",
+		title: "Add a $ JSON path or jq column",
+		free:  true,
+		args:  func(ui *UI) []string { return nil },
+		run:   (*UI).addQueryColumn,
+	},
+	{
+		name:  "filter",
+		title: "Filter the list by a $ JSON path or jq expression, empty clears it",
+		free:  true,
+		args:  func(ui *UI) []string { return nil },
+		run:   (*UI).setFilter,
+	},
+	{
+		name:  "copy_curl",
+		title: "Copy the selected event as a curl command",
+		run:   func(ui *UI, _ string) { ui.copyAsCurl() },
+	},
+	{
+		name:  "restart_tunnel",
+		title: "Restart the tunnel",
+		run:   func(ui *UI, _ string) { ui.restartTunnel() },
+	},
+}
+
+// paletteCommands returns every command, the argument commands followed by
+// the available actions
+func (ui *UI) paletteCommands() []command {
+	commands := append([]command{}, argCommands...)
+	for i := range ui.actions {
+		a := &ui.actions[i]
+		switch a.name {
+		case "up", "down", "palette":
+			continue
+		}
+		if !ui.available(a) {
+			continue
+		}
+		commands = append(commands, command{
+			name:  a.name,
+			title: fmt.Sprintf("%s (%s)", a.title, displayKey(a.key)),
+			run:   func(ui *UI, _ string) { a.run(ui) },
+		})
+	}
+	return commands
+}
+
+// paletteItem is a line of the palette, a command or an argument to one
+type paletteItem struct {
+	text    string
+	detail  string
+	command *command
+}
+
+// showPalette opens the command palette. Typing fuzzy matches commands, a
+// space after a command's name matches its arguments instead. TAB completes
+// the highlighted line and ENTER runs it.
+func (ui *UI) showPalette() *tcell.EventKey {
+	commands := ui.paletteCommands()
+
+	list := tview.NewList().
+		ShowSecondaryText(false)
+	list.SetBorder(true)
+
+	input := tview.NewInputField().
+		SetLabel(": ").
+		SetFieldBackgroundColor(tcell.ColorDefault)
+
+	var items []paletteItem
+	var selected, completionsFor *command
+	var completions []string
+
+	render := func(text string) {
+		name, arg, hasArg := strings.Cut(text, " ")
+		selected = nil
+		if hasArg {
+			for i := range commands {
+				if commands[i].name == name && commands[i].args != nil {
+					selected = &commands[i]
+				}
+			}
+		}
+
+		items = items[:0]
+		if selected == nil {
+			for _, match := range fuzzyFilter(text, len(commands), func(i int) string { return commands[i].name }) {
+				c := &commands[match]
+				items = append(items, paletteItem{text: c.name, detail: c.title, command: c})
+			}
+			list.SetTitle("Commands")
+		} else {
+			if completionsFor != selected {
+				completions = selected.args(ui)
+				completionsFor = selected
+			}
+			arg = strings.TrimSpace(arg)
+			exact := false
+			for _, match := range fuzzyFilter(arg, len(completions), func(i int) string { return completions[i] }) {
+				items = append(items, paletteItem{text: completions[match], command: selected})
+				exact = exact || completions[match] == arg
+			}
+			// Free arguments are run as typed unless a completion is picked
+			if selected.free && arg != "" && !exact {
+				items = append([]paletteItem{{text: arg, detail: "as typed", command: selected}}, items...)
+			}
+			list.SetTitle(selected.title)
+		}
+
+		list.Clear()
+		for _, item := range items {
+			line := tview.Escape(item.text)
+			if item.detail != "" {
+				line += " [gray]" + tview.Escape(item.detail) + "[-]"
+			}
+			list.AddItem(line, "", 0, nil)
+		}
+	}
+
+	run := func(c *command, arg string) {
+		ui.closeModal()
+		c.run(ui, arg)
+	}
+
+	choose := func() {
+		text := input.GetText()
+		index := list.GetCurrentItem()
+		if index < 0 || index >= len(items) {
+			// Free commands run with no completions to pick from
+			if selected != nil && selected.free {
+				_, arg, _ := strings.Cut(text, " ")
+				run(selected, strings.TrimSpace(arg))
+			}
+			return
+		}
+
+		item := items[index]
+		switch {
+		case selected != nil:
+			run(selected, item.text)
+		case item.command.args != nil:
+			input.SetText(item.command.name + " ")
+		default:
+			run(item.command, "")
+		}
+	}
+
+	complete := func() {
+		index := list.GetCurrentItem()
+		if index < 0 || index >= len(items) {
+			return
+		}
+		if selected != nil {
+			input.SetText(selected.name + " " + items[index].text)
+			return
+		}
+		input.SetText(items[index].command.name + " ")
+	}
+
+	input.SetChangedFunc(render)
+	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyUp, tcell.KeyDown, tcell.KeyPgUp, tcell.KeyPgDn:
+			list.InputHandler()(event, nil)
+			return nil
+		case tcell.KeyTab:
+			complete()
+			return nil
+		case tcell.KeyEnter:
+			choose()
+			return nil
+		}
+		return event
+	})
+	render("")
+
+	palette := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(input, 1, 0, true).
+		AddItem(list, 0, 1, false)
+
+	ui.showModal(centered(palette, 90, 25))
+	return nil
+}
+
+// fuzzyFilter returns the indexes of the n candidates that contain the
+// characters of pattern in order, best matches first. An empty pattern keeps
+// every candidate in order.
+func fuzzyFilter(pattern string, n int, candidate func(i int) string) []int {
+	type scored struct {
+		index int
+		score int
+	}
+
+	var matches []scored
+	for i := 0; i < n; i++ {
+		if score, ok := fuzzyScore(pattern, candidate(i)); ok {
+			matches = append(matches, scored{index: i, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	indexes := make([]int, len(matches))
+	for i, m := range matches {
+		indexes[i] = m.index
+	}
+	return indexes
+}
+
+// fuzzyScore matches the characters of pattern in order and case
+// insensitively. Runs of consecutive characters and characters starting
+// words score higher.
+func fuzzyScore(pattern, text string) (int, bool) {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "" {
+		return 0, true
+	}
+
+	textRunes := []rune(strings.ToLower(text))
+	score := 0
+	consecutive := 0
+	t := 0
+	for _, p := range pattern {
+		found := false
+		for ; t < len(textRunes); t++ {
+			if textRunes[t] != p {
+				consecutive = 0
+				continue
+			}
+
+			found = true
+			consecutive++
+			score += consecutive
+			if t == 0 || !unicode.IsLetter(textRunes[t-1]) {
+				score += 3
+			}
+			t++
+			break
+		}
+		if !found {
+			return 0, false
+		}
+	}
+
+	// Shorter candidates matching as much are better
+	return score*10 - len(textRunes), true
+}
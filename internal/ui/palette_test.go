@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyScoreMatches(t *testing.T) {
+	tests := []struct {
+		pattern, text string
+		match         bool
+	}{
+		{"", "Replay event", true},
+		{"  ", "Replay event", true},
+		{"replay", "Replay event", true},
+		{"RE", "replay event", true},
+		{"rpe", "Replay event", true},
+		{"re ev", "Replay event", true},
+		{"év", "Évènement", true},
+		{"eventreplay", "Replay event", false},
+		{"x", "Replay event", false},
+		{"replay event!", "Replay event", false},
+	}
+
+	for _, tt := range tests {
+		if _, ok := fuzzyScore(tt.pattern, tt.text); ok != tt.match {
+			t.Errorf("fuzzyScore(%q, %q) matched = %v, want %v", tt.pattern, tt.text, ok, tt.match)
+		}
+	}
+}
+
+func TestFuzzyScoreRanking(t *testing.T) {
+	tests := []struct {
+		pattern, better, worse string
+	}{
+		// Consecutive characters beat scattered ones
+		{"exp", "Export events", "Edit tags for the selected event"},
+		// Characters starting words beat ones inside them
+		{"se", "Switch session", "Reset filters"},
+		// Shorter candidates win when they match as well
+		{"tag", "Tags", "Tags for the selected event"},
+	}
+
+	for _, tt := range tests {
+		better, _ := fuzzyScore(tt.pattern, tt.better)
+		worse, _ := fuzzyScore(tt.pattern, tt.worse)
+		if better <= worse {
+			t.Errorf("fuzzyScore(%q): %q scored %d, not above %q with %d", tt.pattern, tt.better, better, tt.worse, worse)
+		}
+	}
+}
+
+func TestFuzzyFilter(t *testing.T) {
+	candidates := []string{"Reset filters", "Replay event", "Quit", "Replay all events", "Copy as curl"}
+	candidate := func(i int) string { return candidates[i] }
+
+	if got, want := fuzzyFilter("replay", len(candidates), candidate), []int{1, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("fuzzyFilter(replay) = %v, want %v", got, want)
+	}
+	if got := fuzzyFilter("zzz", len(candidates), candidate); len(got) != 0 {
+		t.Errorf("fuzzyFilter(zzz) = %v, want nothing", got)
+	}
+	// An empty pattern keeps every candidate in order
+	if got, want := fuzzyFilter("", len(candidates), candidate), []int{0, 1, 2, 3, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("fuzzyFilter() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,164 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/lukeberry99/whook/internal/config"
+	"github.com/lukeberry99/whook/internal/expr"
+	"github.com/lukeberry99/whook/internal/jsonpath"
+	"github.com/lukeberry99/whook/internal/storage"
+	"github.com/rivo/tview"
+)
+
+// showQuery opens a pane evaluating a $ JSON path or jq expression against
+// the selected event as it's typed. The expression can be added as a column
+// or used to filter the list.
+func (ui *UI) showQuery() *tcell.EventKey {
+	item, ok := ui.selectedEvent()
+	if !ok {
+		ui.appendLog("Select an event to query")
+		return nil
+	}
+
+	event, err := storage.LoadEvent(item.Path)
+	if err != nil {
+		ui.appendLog(fmt.Sprintf("Error reading %s: %v", item.Filename, err))
+		return nil
+	}
+	doc, err := jsonpath.Decode(event.Event)
+	if err != nil {
+		ui.appendLog(fmt.Sprintf("Error parsing %s: %v", item.Filename, err))
+		return nil
+	}
+
+	results := newDetailText()
+	results.SetBorder(true).SetTitle(tview.Escape(item.Filename))
+
+	input := tview.NewInputField().
+		SetLabel("Query: ").
+		SetFieldBackgroundColor(tcell.ColorDefault).
+		SetPlaceholder("$.content.customer.id or .content | keys")
+	input.SetChangedFunc(func(text string) {
+		results.SetText(ui.evaluateQuery(text, doc))
+		results.ScrollToBeginning()
+	})
+	if ui.filter != nil {
+		input.SetText(ui.filter.String())
+	}
+	input.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			ui.addQueryColumn(input.GetText())
+		}
+	})
+	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyCtrlF {
+			ui.setFilter(input.GetText())
+			return nil
+		}
+		return event
+	})
+
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(fmt.Sprintf("%s: Add as column | %s: Filter the list, empty clears it | %s: Close",
+			ui.theme.accented("ENTER"), ui.theme.accented("Ctrl-F"), ui.theme.accented("ESC")))
+
+	pane := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(input, 1, 0, true).
+		AddItem(results, 0, 1, false).
+		AddItem(help, 1, 0, false)
+	pane.SetBorder(true).SetTitle("JSON Path / jq Query")
+
+	ui.showModal(centered(pane, 120, 35))
+	return nil
+}
+
+// evaluateQuery renders what text produces for doc
+func (ui *UI) evaluateQuery(text string, doc interface{}) string {
+	if strings.TrimSpace(text) == "" {
+		return "[gray]Type a $ JSON path or a jq expression[-]"
+	}
+
+	e, err := expr.Parse(text)
+	if err != nil {
+		return fmt.Sprintf("[red]%s[-]", tview.Escape(err.Error()))
+	}
+
+	values, err := e.Eval(doc)
+	var b strings.Builder
+	for _, value := range values {
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			data = []byte(fmt.Sprint(value))
+		}
+		b.WriteString(ui.theme.highlightJSON(string(data)))
+		b.WriteString("\n")
+	}
+	if err != nil {
+		fmt.Fprintf(&b, "[red]%s[-]\n", tview.Escape(err.Error()))
+	} else if len(values) == 0 {
+		b.WriteString("[gray]No results[-]")
+	}
+	return b.String()
+}
+
+// addQueryColumn shows the expression as a column of the request table.
+// Columns are told apart from built in ones by starting with $ or ., so
+// other jq expressions are piped from .
+func (ui *UI) addQueryColumn(text string) {
+	text = strings.TrimSpace(text)
+	if _, err := expr.Parse(text); err != nil {
+		ui.appendLog(fmt.Sprintf("Invalid query: %v", err))
+		return
+	}
+	if !expr.IsExpr(text) {
+		text = ". | " + text
+	}
+
+	for _, c := range ui.columns {
+		if c.name == text {
+			ui.appendLog(fmt.Sprintf("%s is already a column", text))
+			return
+		}
+	}
+	ui.toggleColumn(text)
+	ui.appendLog(fmt.Sprintf("Added column %s", text))
+}
+
+// setFilter lists only the events text matches, or every event when it's
+// empty, and saves it to the config
+func (ui *UI) setFilter(text string) {
+	text = strings.TrimSpace(text)
+
+	var filter *expr.Expr
+	if text != "" {
+		var err error
+		filter, err = expr.Parse(text)
+		if err != nil {
+			ui.appendLog(fmt.Sprintf("Invalid filter: %v", err))
+			return
+		}
+	}
+
+	ui.filter = filter
+	if ui.config == nil {
+		ui.config = &config.Config{}
+	}
+	ui.config.UI.Filter = text
+	if err := config.Set("", []string{"ui", "filter"}, text); err != nil {
+		ui.appendLog(fmt.Sprintf("Error saving filter: %v", err))
+	}
+
+	ui.closeModal()
+	ui.updateListTitle()
+	ui.refreshFileList()
+	if filter == nil {
+		ui.appendLog("Cleared the filter")
+	} else {
+		ui.appendLog(fmt.Sprintf("Filtering by %s, %d events match", text, len(ui.rows)))
+	}
+}
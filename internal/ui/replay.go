@@ -0,0 +1,134 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/lukeberry99/whook/internal/config"
+	"github.com/lukeberry99/whook/internal/replay"
+	"github.com/lukeberry99/whook/internal/storage"
+)
+
+func (ui *UI) eventService(serviceName string) (string, config.ServiceConfig) {
+	if serviceName == "" && ui.selectedService != "All" {
+		serviceName = ui.selectedService
+	}
+	if ui.config == nil {
+		return serviceName, config.ServiceConfig{}
+	}
+	return serviceName, ui.config.Services[serviceName]
+}
+
+// replaySelected sends the selected event to its service's replay_url
+func (ui *UI) replaySelected() *tcell.EventKey {
+	ui.replayTo("")
+	return nil
+}
+
+// replayTo sends the selected event to a named target or URL, or to its
+// service's replay_url when target is empty. The payload is read from disk
+// so any edits made in $EDITOR are picked up, and it's re-signed with a
+// fresh timestamp.
+func (ui *UI) replayTo(target string) {
+	item, ok := ui.selectedEvent()
+	if !ok {
+		return
+	}
+
+	serviceName, service := ui.eventService(item.ServiceName)
+	if target == "" {
+		if service.ReplayURL == "" {
+			ui.appendLog(fmt.Sprintf("No replay_url configured for service %q", serviceName))
+			return
+		}
+		target = service.ReplayURL
+	} else if ui.config != nil {
+		target = ui.config.ResolveTarget(target)
+	}
+
+	body, err := ui.store.ReadEventBody(item)
+	if err != nil {
+		ui.appendLog(fmt.Sprintf("Error reading event: %v", err))
+		return
+	}
+
+	req, err := replay.ForService(service)
+	if err != nil {
+		ui.appendLog(fmt.Sprintf("Error configuring %s: %v", serviceName, err))
+		return
+	}
+	req.Target = target
+	req.Body = body
+
+	ui.appendLog(fmt.Sprintf("Replaying %s to %s", item.Filename, target))
+	store := ui.store.ForEvent(item.Path)
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		sentAt := time.Now()
+		result, err := replay.Send(ctx, nil, req)
+		recordErr := recordAttempt(store, item.Filename, "replay", target, sentAt, result, err)
+
+		ui.app.QueueUpdateDraw(func() {
+			if recordErr != nil {
+				ui.appendLog(fmt.Sprintf("Error recording the replay of %s: %v", item.Filename, recordErr))
+			}
+			if err != nil {
+				ui.appendLog(fmt.Sprintf("Replay of %s failed: %v", item.Filename, err))
+				return
+			}
+			ui.responseView.SetText(ui.formatResult(result))
+			ui.responseView.ScrollToBeginning()
+			ui.appendLog(fmt.Sprintf("Replay of %s returned %d in %s", item.Filename, result.StatusCode, result.Duration.Round(time.Millisecond)))
+		})
+	}()
+}
+
+// recordAttempt adds a delivery sent from the UI to the event's attempts, so
+// it shows in the attempts tab and the Retries column like the emulator's
+func recordAttempt(store *storage.FileStorage, event, source, target string, sentAt time.Time, result *replay.Result, sendErr error) error {
+	if store.ReadOnly() {
+		return nil
+	}
+
+	previous, err := store.ListAttempts(event)
+	if err != nil {
+		return err
+	}
+
+	attempt := storage.Attempt{
+		Event:  event,
+		Source: source,
+		Target: target,
+		Number: len(previous) + 1,
+		SentAt: sentAt,
+	}
+	if sendErr != nil {
+		attempt.Error = sendErr.Error()
+		attempt.Duration = time.Since(sentAt)
+	} else {
+		attempt.StatusCode = result.StatusCode
+		attempt.Duration = result.Duration
+		attempt.ResponseBody = string(result.Body)
+	}
+
+	return store.RecordAttempt(attempt)
+}
+
+// targetNames returns the names of the configured targets
+func (ui *UI) targetNames() []string {
+	if ui.config == nil {
+		return nil
+	}
+	names := make([]string, 0, len(ui.config.Targets))
+	for name := range ui.config.Targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
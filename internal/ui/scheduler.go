@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showScheduler displays the state of every schedule, p pauses or resumes
+// the scheduler while the view is open
+func (ui *UI) showScheduler() *tcell.EventKey {
+	if ui.scheduler == nil || len(ui.scheduler.Jobs()) == 0 {
+		ui.appendLog("No schedules configured")
+		return nil
+	}
+
+	table := tview.NewTable().
+		SetFixed(1, 0).
+		SetSelectable(true, false)
+	table.SetBorder(true)
+
+	ui.renderScheduler(table)
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Rune() == 'p' {
+			if ui.scheduler.Paused() {
+				ui.scheduler.Resume()
+				ui.appendLog("Scheduler resumed")
+			} else {
+				ui.scheduler.Pause()
+				ui.appendLog("Scheduler paused")
+			}
+			ui.renderScheduler(table)
+			return nil
+		}
+		return event
+	})
+
+	// Keep the next run times and results current while the view is open
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			open := true
+			ui.app.QueueUpdateDraw(func() {
+				open = ui.isModalVisible && ui.app.GetFocus() == table
+				if open {
+					ui.renderScheduler(table)
+				}
+			})
+			if !open {
+				return
+			}
+		}
+	}()
+
+	ui.showModal(centered(table, 120, 20))
+	return nil
+}
+
+func (ui *UI) renderScheduler(table *tview.Table) {
+	state := "[green]running[-]"
+	if ui.scheduler.Paused() {
+		state = ui.theme.accented("paused")
+	}
+	table.SetTitle(fmt.Sprintf("Scheduler (%s) p: Pause/Resume", state))
+
+	table.Clear()
+	for col, header := range []string{"Name", "Service", "Schedule", "Runs", "Last Run", "Next Run", "Last Result"} {
+		table.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(ui.theme.accent).
+			SetSelectable(false))
+	}
+
+	for i, job := range ui.scheduler.Jobs() {
+		lastRun := "-"
+		if !job.LastRun.IsZero() {
+			lastRun = job.LastRun.Format("15:04:05")
+		}
+		nextRun := "-"
+		if !job.NextRun.IsZero() {
+			nextRun = job.NextRun.Format("15:04:05")
+		}
+
+		row := i + 1
+		table.SetCell(row, 0, tview.NewTableCell(job.Name))
+		table.SetCell(row, 1, tview.NewTableCell(job.Service))
+		table.SetCell(row, 2, tview.NewTableCell(job.Schedule))
+		table.SetCell(row, 3, tview.NewTableCell(fmt.Sprint(job.Runs)).SetAlign(tview.AlignRight))
+		table.SetCell(row, 4, tview.NewTableCell(lastRun))
+		table.SetCell(row, 5, tview.NewTableCell(nextRun))
+		table.SetCell(row, 6, tview.NewTableCell(tview.Escape(job.LastResult)).SetExpansion(1))
+	}
+}
@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// serviceCount is a service in the picker with how many events it has and
+// how many of them arrived since the UI started without being viewed
+type serviceCount struct {
+	name   string
+	events int
+	unread int
+}
+
+// serviceCounts merges the configured services with the ones events were
+// stored for, which includes services routed by path without configuration
+func (ui *UI) serviceCounts() ([]serviceCount, error) {
+	items, err := ui.store.ListAll()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]*serviceCount{}
+	if ui.config != nil {
+		for name := range ui.config.Services {
+			counts[name] = &serviceCount{name: name}
+		}
+	}
+
+	all := serviceCount{name: "All"}
+	for _, item := range items {
+		unread := ui.isUnread(item.Path, item.Time)
+		all.events++
+		if unread {
+			all.unread++
+		}
+
+		// Events stored without a service are only listed under All
+		if item.ServiceName == "" {
+			continue
+		}
+		c, ok := counts[item.ServiceName]
+		if !ok {
+			c = &serviceCount{name: item.ServiceName}
+			counts[item.ServiceName] = c
+		}
+
+		c.events++
+		if unread {
+			c.unread++
+		}
+	}
+
+	services := make([]serviceCount, 0, len(counts)+1)
+	for _, c := range counts {
+		services = append(services, *c)
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].name < services[j].name })
+
+	return append([]serviceCount{all}, services...), nil
+}
+
+// serviceNames returns All followed by the name of every service
+func (ui *UI) serviceNames() []string {
+	services, err := ui.serviceCounts()
+	if err != nil {
+		ui.appendLog(fmt.Sprintf("Error listing services: %v", err))
+		return nil
+	}
+
+	names := make([]string, len(services))
+	for i, c := range services {
+		names[i] = c.name
+	}
+	return names
+}
+
+// showServices lists the services to pick from, typing filters them
+func (ui *UI) showServices() *tcell.EventKey {
+	services, err := ui.serviceCounts()
+	if err != nil {
+		ui.appendLog(fmt.Sprintf("Error listing services: %v", err))
+		return nil
+	}
+
+	list := tview.NewList()
+	list.SetBorder(true).SetTitle("Select Service")
+
+	var shown []serviceCount
+	render := func(filter string) {
+		list.Clear()
+		shown = shown[:0]
+
+		filter = strings.ToLower(strings.TrimSpace(filter))
+		for _, c := range services {
+			if filter != "" && !strings.Contains(strings.ToLower(c.name), filter) {
+				continue
+			}
+			shown = append(shown, c)
+
+			name := tview.Escape(c.name)
+			if c.name == ui.selectedService {
+				name += " " + ui.theme.accented("(current)")
+			}
+			secondary := fmt.Sprintf("%d events", c.events)
+			if c.unread > 0 {
+				secondary += fmt.Sprintf(", [green]%d unread[-]", c.unread)
+			}
+			list.AddItem(name, secondary, 0, nil)
+		}
+	}
+	render("")
+	for i, c := range shown {
+		if c.name == ui.selectedService {
+			list.SetCurrentItem(i)
+		}
+	}
+
+	choose := func() {
+		index := list.GetCurrentItem()
+		if index < 0 || index >= len(shown) {
+			return
+		}
+		ui.closeModal()
+		ui.selectService(shown[index].name)
+	}
+	list.SetSelectedFunc(func(int, string, string, rune) { choose() })
+
+	// The filter keeps the focus, the arrow keys and enter work on the list
+	input := tview.NewInputField().
+		SetLabel("Filter: ").
+		SetFieldBackgroundColor(tcell.ColorDefault).
+		SetChangedFunc(render)
+	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyUp, tcell.KeyDown, tcell.KeyPgUp, tcell.KeyPgDn:
+			list.InputHandler()(event, nil)
+			return nil
+		case tcell.KeyEnter:
+			choose()
+			return nil
+		}
+		return event
+	})
+
+	picker := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(input, 1, 0, true).
+		AddItem(list, 0, 1, false)
+
+	ui.showModal(centered(picker, 60, min(len(services)*2+3, 30)))
+	return nil
+}
+
+func (ui *UI) selectService(name string) {
+	if name == "All" {
+		ui.store.SetSelectedService("")
+	} else {
+		ui.store.SetSelectedService(name)
+	}
+	ui.selectedService = name
+	ui.updateListTitle()
+	ui.refreshFileList()
+}
+
+// isUnread reports whether an event arrived since the UI started and hasn't
+// been viewed
+func (ui *UI) isUnread(path string, received time.Time) bool {
+	return received.After(ui.startedAt) && !ui.read[path]
+}
@@ -0,0 +1,199 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/lukeberry99/whook/internal/query"
+	"github.com/lukeberry99/whook/internal/storage"
+	"github.com/rivo/tview"
+)
+
+// showSessions lists the sessions, enter switches to one and c compares the
+// highlighted session with the current one
+func (ui *UI) showSessions() *tcell.EventKey {
+	sessions, err := ui.store.Sessions()
+	if err != nil {
+		ui.appendLog(fmt.Sprintf("Error listing sessions: %v", err))
+		return nil
+	}
+
+	list := tview.NewList()
+	list.SetTitle("Sessions (c: Compare with current)").SetBorder(true)
+
+	for _, session := range sessions {
+		session := session
+
+		count := 0
+		if view, err := ui.store.InSession(session.Name); err == nil {
+			if items, err := view.ListAll(); err == nil {
+				count = len(items)
+			}
+		}
+
+		name := session.DisplayName()
+		if session.Name == ui.store.Session() {
+			name += " " + ui.theme.accented("(current)")
+		}
+		secondary := fmt.Sprintf("%d events", count)
+		if !session.CreatedAt.IsZero() {
+			secondary += " | Created " + session.CreatedAt.Format("02/01/2006 15:04")
+		}
+		if session.Retention > 0 {
+			secondary += " | Kept for " + session.Retention.String()
+		}
+
+		list.AddItem(name, secondary, 0, func() {
+			ui.closeModal()
+			ui.switchSession(session)
+		})
+	}
+
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Rune() == 'c' {
+			index := list.GetCurrentItem()
+			if index >= 0 && index < len(sessions) {
+				ui.compareSessions(sessions[index])
+			}
+			return nil
+		}
+		return event
+	})
+
+	ui.showModal(centered(list, 80, 20))
+	return nil
+}
+
+func (ui *UI) switchSession(session storage.Session) {
+	if err := ui.store.SetSession(session.Name); err != nil {
+		ui.appendLog(fmt.Sprintf("Error switching session: %v", err))
+		return
+	}
+
+	ui.updateListTitle()
+	ui.refreshFileList()
+	ui.appendLog(fmt.Sprintf("Switched to session %s, new webhooks are stored in it", session.DisplayName()))
+}
+
+// sessionNames returns the names the sessions are shown with
+func (ui *UI) sessionNames() []string {
+	sessions, err := ui.store.Sessions()
+	if err != nil {
+		ui.appendLog(fmt.Sprintf("Error listing sessions: %v", err))
+		return nil
+	}
+
+	names := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		names = append(names, session.DisplayName())
+	}
+	return names
+}
+
+// switchSessionNamed switches to the session shown as name
+func (ui *UI) switchSessionNamed(name string) {
+	sessions, err := ui.store.Sessions()
+	if err != nil {
+		ui.appendLog(fmt.Sprintf("Error listing sessions: %v", err))
+		return
+	}
+
+	for _, session := range sessions {
+		if session.DisplayName() == name {
+			ui.switchSession(session)
+			return
+		}
+	}
+	ui.appendLog(fmt.Sprintf("No session named %s", name))
+}
+
+type sessionCount struct {
+	service   string
+	eventType string
+}
+
+// compareSessions shows how many events of each service and type the current
+// session and other hold side by side
+func (ui *UI) compareSessions(other storage.Session) {
+	currentName := ui.store.Session()
+
+	counts := map[sessionCount][2]int{}
+	for i, name := range []string{currentName, other.Name} {
+		view, err := ui.store.InSession(name)
+		if err != nil {
+			ui.appendLog(fmt.Sprintf("Error opening session: %v", err))
+			return
+		}
+		matches, err := query.Run(view, ui.config, query.Query{})
+		if err != nil {
+			ui.appendLog(fmt.Sprintf("Error reading session: %v", err))
+			return
+		}
+
+		for _, match := range matches {
+			key := sessionCount{service: match.Item.ServiceName, eventType: match.EventType}
+			c := counts[key]
+			c[i]++
+			counts[key] = c
+		}
+	}
+
+	keys := make([]sessionCount, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].service != keys[j].service {
+			return keys[i].service < keys[j].service
+		}
+		return keys[i].eventType < keys[j].eventType
+	})
+
+	current := storage.Session{Name: currentName}
+	table := tview.NewTable().
+		SetFixed(1, 0).
+		SetSelectable(true, false)
+	table.SetTitle(fmt.Sprintf("%s vs %s", current.DisplayName(), other.DisplayName())).SetBorder(true)
+
+	for col, header := range []string{"Service", "Event Type", current.DisplayName(), other.DisplayName(), "Difference"} {
+		table.SetCell(0, col, tview.NewTableCell(tview.Escape(header)).
+			SetTextColor(ui.theme.accent).
+			SetSelectable(false))
+	}
+
+	var totals [2]int
+	for i, key := range keys {
+		c := counts[key]
+		totals[0] += c[0]
+		totals[1] += c[1]
+		ui.setComparisonRow(table, i+1, key.service, key.eventType, c)
+	}
+	ui.setComparisonRow(table, len(keys)+1, "Total", "", totals)
+
+	ui.showModal(centered(table, 100, 25))
+}
+
+func (ui *UI) setComparisonRow(table *tview.Table, row int, service, eventType string, counts [2]int) {
+	if service == "" {
+		service = "-"
+	}
+	if eventType == "" {
+		eventType = "-"
+	}
+
+	diff := counts[1] - counts[0]
+	color := ui.theme.styles.PrimaryTextColor
+	switch {
+	case diff > 0:
+		color = ui.theme.good
+	case diff < 0:
+		color = ui.theme.bad
+	}
+
+	table.SetCell(row, 0, tview.NewTableCell(tview.Escape(service)))
+	table.SetCell(row, 1, tview.NewTableCell(tview.Escape(eventType)).SetExpansion(1))
+	table.SetCell(row, 2, tview.NewTableCell(fmt.Sprint(counts[0])).SetAlign(tview.AlignRight))
+	table.SetCell(row, 3, tview.NewTableCell(fmt.Sprint(counts[1])).SetAlign(tview.AlignRight))
+	table.SetCell(row, 4, tview.NewTableCell(fmt.Sprintf("%+d", diff)).SetAlign(tview.AlignRight).SetTextColor(color))
+}
@@ -0,0 +1,350 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/lukeberry99/whook/internal/config"
+	"github.com/rivo/tview"
+)
+
+// dashboardInterval is how often the dashboard is redrawn between events, so
+// the traffic graph and uptime move on
+const dashboardInterval = 5 * time.Second
+
+// trafficMinutes is how far back the traffic graph goes
+const trafficMinutes = 60
+
+// dashboard shows statistics about every event of the current session. Its
+// rows are listed and loaded off the UI goroutine, like the request table's
+// they keep what's known about events that haven't changed, and only the
+// rendering happens on the UI goroutine.
+type dashboard struct {
+	view *tview.TextView
+	wake chan struct{}
+	done chan struct{}
+
+	mu sync.Mutex
+	// retries is set when the attempt logs have to be read again
+	retries bool
+	// selected is the selected service, which events stored without a
+	// service belong to
+	selected string
+	services map[string]config.ServiceConfig
+
+	// rows and cache are only used by the dashboard's goroutine
+	rows  []*requestRow
+	cache map[string]*requestRow
+}
+
+// dashboardStats is what the dashboard shows, gathered from its rows
+type dashboardStats struct {
+	events     int
+	perMinute  []int
+	services   map[string]int
+	eventTypes map[string]int
+	statuses   map[string]int
+	signatures map[string]int
+	// latencies are sorted
+	latencies []time.Duration
+	err       error
+}
+
+// showDashboard opens the statistics, they're updated as events arrive
+func (ui *UI) showDashboard() *tcell.EventKey {
+	d := &dashboard{
+		view: tview.NewTextView().
+			SetDynamicColors(true).
+			SetWrap(false),
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		cache:    map[string]*requestRow{},
+		retries:  true,
+		selected: ui.selectedService,
+	}
+	if ui.config != nil {
+		d.services = ui.config.Services
+	}
+	d.view.SetBorder(true).SetTitle("Statistics (ESC: Close)")
+	d.view.SetText("[gray]Loading events...[-]")
+
+	ui.dashboard = d
+	ui.showModal(centered(d.view, 110, 45))
+
+	go ui.runDashboard(d)
+	d.wake <- struct{}{}
+
+	return nil
+}
+
+func (ui *UI) closeDashboard() {
+	if ui.dashboard == nil {
+		return
+	}
+	close(ui.dashboard.done)
+	ui.dashboard = nil
+}
+
+// reloadDashboard asks the dashboard to list the session's events again,
+// retries reads every event's attempt log again too
+func (ui *UI) reloadDashboard(retries bool) {
+	d := ui.dashboard
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	d.retries = d.retries || retries
+	d.selected = ui.selectedService
+	d.mu.Unlock()
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+		// A reload is already waiting
+	}
+}
+
+// runDashboard reloads the rows when asked and gathers the statistics every
+// dashboardInterval, so the traffic graph and uptime move on between events
+func (ui *UI) runDashboard(d *dashboard) {
+	ticker := time.NewTicker(dashboardInterval)
+	defer ticker.Stop()
+
+	var err error
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-d.wake:
+			err = ui.loadDashboardRows(d)
+		case <-ticker.C:
+		}
+
+		stats := d.collect(time.Now())
+		stats.err = err
+		ui.app.QueueUpdateDraw(func() {
+			if ui.dashboard == d {
+				ui.renderDashboard(d, stats)
+			}
+		})
+	}
+}
+
+// loadDashboardRows lists the session's events and loads the rows of new
+// and changed ones
+func (ui *UI) loadDashboardRows(d *dashboard) error {
+	d.mu.Lock()
+	retries := d.retries
+	selected := d.selected
+	d.retries = false
+	d.mu.Unlock()
+
+	items, err := ui.store.ListAll()
+	if err != nil {
+		return err
+	}
+
+	cache := make(map[string]*requestRow, len(items))
+	rows := make([]*requestRow, 0, len(items))
+	for _, item := range items {
+		row, ok := d.cache[item.Path]
+		if !ok || !row.item.ModTime.Equal(item.ModTime) {
+			row = &requestRow{signature: "-"}
+		}
+		row.item = item
+		if retries {
+			row.retriesLoaded = false
+		}
+		service := item.ServiceName
+		if service == "" && selected != "All" {
+			service = selected
+		}
+		ui.loadRowWith(row, d.services[service])
+
+		cache[item.Path] = row
+		rows = append(rows, row)
+	}
+	d.cache = cache
+	d.rows = rows
+
+	return nil
+}
+
+// collect gathers the statistics of the dashboard's rows
+func (d *dashboard) collect(now time.Time) dashboardStats {
+	stats := dashboardStats{
+		events:     len(d.rows),
+		perMinute:  make([]int, trafficMinutes),
+		services:   map[string]int{},
+		eventTypes: map[string]int{},
+		statuses:   map[string]int{},
+		signatures: map[string]int{},
+	}
+
+	for _, row := range d.rows {
+		if minute := int(now.Sub(row.item.Time) / time.Minute); minute >= 0 && minute < trafficMinutes {
+			stats.perMinute[trafficMinutes-1-minute]++
+		}
+
+		stats.services[row.item.ServiceName]++
+
+		eventType := row.eventType
+		if eventType == "" {
+			eventType = "-"
+		}
+		stats.eventTypes[row.item.ServiceName+" "+eventType]++
+
+		status := "none"
+		if row.status != 0 {
+			status = fmt.Sprint(row.status)
+		}
+		stats.statuses[status]++
+
+		stats.signatures[row.signature]++
+		stats.latencies = append(stats.latencies, row.latencies...)
+	}
+	sort.Slice(stats.latencies, func(i, j int) bool { return stats.latencies[i] < stats.latencies[j] })
+
+	return stats
+}
+
+type statCount struct {
+	name  string
+	count int
+}
+
+func sortedCounts(counts map[string]int) []statCount {
+	sorted := make([]statCount, 0, len(counts))
+	for name, count := range counts {
+		sorted = append(sorted, statCount{name: name, count: count})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].count != sorted[j].count {
+			return sorted[i].count > sorted[j].count
+		}
+		return sorted[i].name < sorted[j].name
+	})
+	return sorted
+}
+
+func (ui *UI) renderDashboard(d *dashboard, stats dashboardStats) {
+	if stats.err != nil {
+		d.view.SetText(fmt.Sprintf("[red]Error listing events: %s[-]", tview.Escape(stats.err.Error())))
+		return
+	}
+
+	now := time.Now()
+	perMinute := stats.perMinute
+	latencies := stats.latencies
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %d", ui.theme.accented("Events"), stats.events)
+	if session := ui.store.Session(); session != "" {
+		fmt.Fprintf(&b, " in session %s", tview.Escape(session))
+	}
+	b.WriteString("\n\n")
+
+	lastHour := 0
+	peak := 0
+	for _, count := range perMinute {
+		lastHour += count
+		peak = max(peak, count)
+	}
+	fmt.Fprintf(&b, "%s [gray](last %d minutes, peak %d, last minute %d, last hour %d)[-]\n",
+		ui.theme.accented("Events per minute"), trafficMinutes, peak, perMinute[trafficMinutes-1], lastHour)
+	fmt.Fprintf(&b, "[green]%s[-]\n\n", sparkline(perMinute))
+
+	b.WriteString(ui.theme.accented("Tunnel") + " ")
+	provider, url, upSince := ui.tunnel.Get()
+	if upSince.IsZero() {
+		b.WriteString("[gray]not running[-]\n\n")
+	} else {
+		fmt.Fprintf(&b, "%s %s, up for %s\n\n", tview.Escape(provider), tview.Escape(url), now.Sub(upSince).Round(time.Second))
+	}
+
+	ui.writeCounts(&b, "Services", stats.services, 0)
+	ui.writeCounts(&b, "Event types", stats.eventTypes, 10)
+	ui.writeCounts(&b, "Response codes", stats.statuses, 0)
+
+	fmt.Fprintf(&b, "%s\n  [green]valid[-] %d  [red]failed[-] %d  [gray]not checked[-] %d\n\n",
+		ui.theme.accented("Signatures"), stats.signatures["valid"], stats.signatures["invalid"], stats.signatures["-"]+stats.signatures["redacted"])
+
+	fmt.Fprintf(&b, "%s [gray](%d attempts)[-]\n", ui.theme.accented("Forwarding latency"), len(latencies))
+	if len(latencies) == 0 {
+		b.WriteString("  [gray]No forwarding or replay attempts have been recorded[-]\n")
+	} else {
+		fmt.Fprintf(&b, "  p50 %s  p90 %s  p99 %s  max %s\n",
+			percentile(latencies, 0.5), percentile(latencies, 0.9), percentile(latencies, 0.99), latencies[len(latencies)-1].Round(time.Millisecond))
+	}
+
+	d.view.SetText(b.String())
+}
+
+// writeCounts lists counts largest first with a bar each, limit leaves out
+// all but the largest when it isn't 0
+func (ui *UI) writeCounts(b *strings.Builder, title string, counts map[string]int, limit int) {
+	b.WriteString(ui.theme.accented(title) + "\n")
+
+	sorted := sortedCounts(counts)
+	if len(sorted) == 0 {
+		b.WriteString("  [gray]None[-]\n\n")
+		return
+	}
+
+	for i, c := range sorted {
+		if limit > 0 && i == limit {
+			fmt.Fprintf(b, "  [gray]and %d more[-]\n", len(sorted)-limit)
+			break
+		}
+		width := 30 * c.count / sorted[0].count
+		fmt.Fprintf(b, "  %-40s %6d [blue]%s[-]\n", tview.Escape(c.name), c.count, strings.Repeat("█", max(width, 1)))
+	}
+	b.WriteString("\n")
+}
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws a block per count, scaled to the largest
+func sparkline(counts []int) string {
+	peak := 0
+	for _, count := range counts {
+		peak = max(peak, count)
+	}
+
+	var b strings.Builder
+	for _, count := range counts {
+		if peak == 0 || count == 0 {
+			b.WriteRune(' ')
+			continue
+		}
+		b.WriteRune(sparkBlocks[(count*(len(sparkBlocks)-1)+peak-1)/peak])
+	}
+	return b.String()
+}
+
+// percentile returns the qth percentile of sorted durations
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	return sorted[int(q*float64(len(sorted)-1))].Round(time.Millisecond)
+}
+
+// restartTunnel restarts the tunnel in the background, it takes a while for
+// the new URL to be known
+func (ui *UI) restartTunnel() {
+	ui.appendLog("Restarting the tunnel...")
+	go func() {
+		url, err := ui.tunnel.Restart()
+		ui.app.QueueUpdateDraw(func() {
+			if err != nil {
+				ui.appendLog(fmt.Sprintf("Error restarting the tunnel: %v", err))
+				return
+			}
+			ui.appendLog(fmt.Sprintf("Tunnel URL: %s", url))
+			ui.reloadDashboard(false)
+		})
+	}()
+}
@@ -0,0 +1,637 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/lukeberry99/whook/internal/config"
+	"github.com/lukeberry99/whook/internal/eventtype"
+	"github.com/lukeberry99/whook/internal/expr"
+	"github.com/lukeberry99/whook/internal/jsonpath"
+	"github.com/lukeberry99/whook/internal/replay"
+	"github.com/lukeberry99/whook/internal/signing"
+	"github.com/lukeberry99/whook/internal/storage"
+	"github.com/rivo/tview"
+)
+
+// rowPageSize is how many events are read at once as rows are drawn
+const rowPageSize = 50
+
+// requestRow is an event with everything the request table shows about it.
+// Only the listing is known until the row is loaded.
+type requestRow struct {
+	item          storage.EventListItem
+	loaded        bool
+	retriesLoaded bool
+	event         *storage.StoredEvent
+	eventType     string
+	size          int
+	status        int
+	signature     string
+	retries       int
+	latencies     []time.Duration
+	payload       interface{}
+	payloadErr    error
+	// values caches what expression columns produce for the payload, so
+	// they're evaluated once per row rather than every time it's drawn
+	values map[string]string
+}
+
+// requestColumn is a column of the request table. Columns sort by their text
+// unless they set less. Listed columns only need the event listing, so their
+// rows don't have to be loaded.
+type requestColumn struct {
+	name   string
+	title  string
+	align  int
+	listed bool
+	value  func(row *requestRow) string
+	less   func(a, b *requestRow) bool
+}
+
+var requestColumns = []requestColumn{
+	{
+		name:   "time",
+		title:  "Time",
+		listed: true,
+		value:  func(row *requestRow) string { return row.item.ReceivedAt },
+		less:   func(a, b *requestRow) bool { return a.item.Time.Before(b.item.Time) },
+	},
+	{name: "service", title: "Service", listed: true, value: func(row *requestRow) string { return row.item.ServiceName }},
+	{name: "event_type", title: "Event Type", value: func(row *requestRow) string { return row.eventType }},
+	{name: "method", title: "Method", value: func(row *requestRow) string { return row.stored().Method }},
+	{name: "path", title: "Path", value: func(row *requestRow) string { return row.stored().Path }},
+	{
+		name:  "size",
+		title: "Size",
+		align: tview.AlignRight,
+		value: func(row *requestRow) string { return formatSize(row.size) },
+		less:  func(a, b *requestRow) bool { return a.size < b.size },
+	},
+	{
+		name:  "status",
+		title: "Status",
+		align: tview.AlignRight,
+		value: func(row *requestRow) string { return countText(row.status) },
+		less:  func(a, b *requestRow) bool { return a.status < b.status },
+	},
+	{name: "signature", title: "Signature", value: func(row *requestRow) string { return row.signature }},
+	{
+		name:  "retries",
+		title: "Retries",
+		align: tview.AlignRight,
+		value: func(row *requestRow) string { return countText(row.retries) },
+		less:  func(a, b *requestRow) bool { return a.retries < b.retries },
+	},
+	{name: "filename", title: "File", listed: true, value: func(row *requestRow) string { return row.item.Filename }},
+}
+
+// defaultRequestColumns are shown until columns are chosen with C
+var defaultRequestColumns = []string{"time", "service", "event_type", "method", "path", "status"}
+
+func (row *requestRow) stored() *storage.StoredEvent {
+	if row.event == nil {
+		return &storage.StoredEvent{}
+	}
+	return row.event
+}
+
+func countText(n int) string {
+	if n == 0 {
+		return "-"
+	}
+	return fmt.Sprint(n)
+}
+
+func formatSize(size int) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
+
+// column returns a built in column by name, or a column showing the result of
+// a $ JSON path or jq expression on the payload
+func column(name string) (requestColumn, bool) {
+	for _, c := range requestColumns {
+		if c.name == name {
+			return c, true
+		}
+	}
+
+	if !expr.IsExpr(name) {
+		return requestColumn{}, false
+	}
+	e, err := expr.Parse(name)
+	if err != nil {
+		return requestColumn{}, false
+	}
+
+	return requestColumn{
+		name:  name,
+		title: name,
+		value: func(row *requestRow) string {
+			if value, ok := row.values[name]; ok {
+				return value
+			}
+
+			var value string
+			if row.payloadErr == nil {
+				value = e.Format(row.payload)
+			}
+			if row.loaded {
+				if row.values == nil {
+					row.values = map[string]string{}
+				}
+				row.values[name] = value
+			}
+			return value
+		},
+	}, true
+}
+
+// tableColumns returns the configured columns, skipping unknown ones
+func (ui *UI) tableColumns() []requestColumn {
+	names := defaultRequestColumns
+	if ui.config != nil && len(ui.config.UI.Columns) > 0 {
+		names = ui.config.UI.Columns
+	}
+
+	columns := make([]requestColumn, 0, len(names))
+	for _, name := range names {
+		if c, ok := column(name); ok {
+			columns = append(columns, c)
+		}
+	}
+	if len(columns) == 0 {
+		c, _ := column("time")
+		columns = append(columns, c)
+	}
+
+	return columns
+}
+
+// updateRows replaces the rows with the listed events. Rows of events that
+// haven't changed keep what was loaded, except for their retries, which are
+// recorded in separate files. With a filter, every row is loaded and only
+// the matching ones are kept.
+func (ui *UI) updateRows(items []storage.EventListItem) {
+	cache := make(map[string]*requestRow, len(items))
+	rows := make([]*requestRow, 0, len(items))
+	for _, item := range items {
+		row, ok := ui.rowCache[item.Path]
+		if !ok || !row.item.ModTime.Equal(item.ModTime) {
+			row = &requestRow{signature: "-"}
+		}
+		row.item = item
+		row.retriesLoaded = false
+
+		cache[item.Path] = row
+		if ui.filter != nil {
+			ui.loadRow(row)
+			if row.payloadErr != nil || !ui.filter.Matches(row.payload) {
+				continue
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	ui.rowCache = cache
+	ui.rows = rows
+}
+
+// loadPage loads the page of rows holding the row at index
+func (ui *UI) loadPage(index int) {
+	start := index - index%rowPageSize
+	end := min(start+rowPageSize, len(ui.rows))
+	for _, row := range ui.rows[start:end] {
+		ui.loadRow(row)
+	}
+}
+
+func (ui *UI) loadAllRows() {
+	for _, row := range ui.rows {
+		ui.loadRow(row)
+	}
+}
+
+// loadRow reads the event for the columns that need its contents
+func (ui *UI) loadRow(row *requestRow) {
+	_, service := ui.eventService(row.item.ServiceName)
+	ui.loadRowWith(row, service)
+}
+
+// loadRowWith loads a row with the configuration of its service, it doesn't
+// touch the UI's state so it can run off the UI goroutine
+func (ui *UI) loadRowWith(row *requestRow, service config.ServiceConfig) {
+	item := row.item
+
+	if !row.retriesLoaded {
+		row.retriesLoaded = true
+		row.retries = 0
+		row.latencies = nil
+		if attempts, err := ui.store.ForEvent(item.Path).ListAttempts(item.Filename); err == nil {
+			for _, attempt := range attempts {
+				if attempt.Number > 1 {
+					row.retries++
+				}
+				if attempt.Error == "" {
+					row.latencies = append(row.latencies, attempt.Duration)
+				}
+			}
+		}
+	}
+
+	if row.loaded {
+		return
+	}
+	row.loaded = true
+
+	event, err := storage.LoadEvent(item.Path)
+	if err != nil {
+		return
+	}
+	row.event = event
+
+	body := event.Body()
+	row.eventType = eventtype.Extract(service, event.Headers, body)
+	row.size = len(body)
+	if event.Response != nil {
+		row.status = event.Response.Status
+	}
+	row.payload, row.payloadErr = jsonpath.Decode(event.Event)
+
+	if service.Signing.Scheme != "" {
+		err := replay.Verify(service, event.Headers, body)
+		switch {
+		case err == nil:
+			row.signature = "valid"
+		case errors.Is(err, signing.ErrNotSigned):
+		case errors.Is(err, signing.ErrRedacted):
+			row.signature = "redacted"
+		default:
+			row.signature = "invalid"
+		}
+	}
+}
+
+// sortColumnOf returns the column rows are sorted by, falling back to time
+func (ui *UI) sortColumnOf() requestColumn {
+	c, ok := column(ui.sortColumn)
+	if !ok {
+		c, _ = column("time")
+	}
+	return c
+}
+
+// rowLess orders rows by the sort column and direction
+func (ui *UI) rowLess(c requestColumn) func(a, b *requestRow) bool {
+	less := c.less
+	if less == nil {
+		less = func(a, b *requestRow) bool {
+			return strings.ToLower(c.value(a)) < strings.ToLower(c.value(b))
+		}
+	}
+	if ui.sortDesc {
+		return func(a, b *requestRow) bool { return less(b, a) }
+	}
+	return less
+}
+
+// sortRows orders rows by the sort column, falling back to newest first
+func (ui *UI) sortRows(rows []*requestRow) {
+	c := ui.sortColumnOf()
+
+	// Only the listing is needed to sort by a listed column, others need
+	// every row loaded
+	if !c.listed {
+		ui.loadAllRows()
+	}
+
+	less := ui.rowLess(c)
+	sort.SliceStable(rows, func(i, j int) bool { return less(rows[i], rows[j]) })
+}
+
+// applyChanges updates the rows with the events that changed, leaving the
+// others as they are. New rows are put in place rather than sorting again,
+// and only they are loaded for the filter or sort column.
+func (ui *UI) applyChanges(changes storage.EventChanges) {
+	if changes.All {
+		ui.refreshFileList()
+		return
+	}
+
+	ui.keepSelection(func() {
+		// Retries are read again for the rows as they're drawn
+		if changes.Attempts {
+			for _, row := range ui.rowCache {
+				row.retriesLoaded = false
+			}
+		}
+
+		replaced := map[string]bool{}
+		for _, path := range changes.Removed {
+			replaced[path] = true
+			delete(ui.rowCache, path)
+		}
+		for _, item := range changes.Changed {
+			replaced[item.Path] = true
+		}
+		if len(replaced) == 0 {
+			return
+		}
+
+		rows := ui.rows[:0]
+		for _, row := range ui.rows {
+			if !replaced[row.item.Path] {
+				rows = append(rows, row)
+			}
+		}
+
+		c := ui.sortColumnOf()
+		less := ui.rowLess(c)
+		for _, item := range changes.Changed {
+			row, ok := ui.rowCache[item.Path]
+			if !ok || !row.item.ModTime.Equal(item.ModTime) {
+				row = &requestRow{signature: "-"}
+			}
+			row.item = item
+			row.retriesLoaded = false
+			ui.rowCache[item.Path] = row
+
+			if ui.filter != nil {
+				ui.loadRow(row)
+				if row.payloadErr != nil || !ui.filter.Matches(row.payload) {
+					continue
+				}
+			}
+			if !c.listed {
+				ui.loadRow(row)
+			}
+
+			i := sort.Search(len(rows), func(i int) bool { return less(row, rows[i]) })
+			rows = append(rows, nil)
+			copy(rows[i+1:], rows[i:])
+			rows[i] = row
+		}
+		ui.rows = rows
+	})
+}
+
+// requestContent serves the request table's cells from the rows, loading
+// them a page at a time as they're drawn so large stores open quickly
+type requestContent struct {
+	tview.TableContentReadOnly
+	ui *UI
+}
+
+func (c *requestContent) GetRowCount() int {
+	return len(c.ui.rows) + 1
+}
+
+func (c *requestContent) GetColumnCount() int {
+	return len(c.ui.columns)
+}
+
+func (c *requestContent) GetCell(row, column int) *tview.TableCell {
+	ui := c.ui
+	if column < 0 || column >= len(ui.columns) {
+		return nil
+	}
+	col := ui.columns[column]
+
+	if row == 0 {
+		title := col.title
+		if col.name == ui.sortColumn {
+			if ui.sortDesc {
+				title += " ▼"
+			} else {
+				title += " ▲"
+			}
+		}
+		return tview.NewTableCell(tview.Escape(title)).
+			SetTextColor(ui.theme.accent).
+			SetAlign(col.align).
+			SetSelectable(false)
+	}
+
+	index := row - 1
+	if index < 0 || index >= len(ui.rows) {
+		return nil
+	}
+	r := ui.rows[index]
+	if !col.listed {
+		ui.loadPage(index)
+	}
+
+	cell := tview.NewTableCell(tview.Escape(col.value(r))).
+		SetAlign(col.align).
+		SetMaxWidth(40)
+	switch {
+	case col.name == "signature" && r.signature == "invalid":
+		cell.SetTextColor(ui.theme.bad)
+	case col.name == "status" && r.status >= 400:
+		cell.SetTextColor(ui.theme.bad)
+	case ui.isMarked(r.item.Path):
+		cell.SetTextColor(ui.theme.marked)
+	}
+	return cell
+}
+
+// renderTable sorts the rows, keeping the selected event selected
+func (ui *UI) renderTable() {
+	ui.keepSelection(func() { ui.sortRows(ui.rows) })
+}
+
+// keepSelection runs change, which replaces or reorders the rows, and then
+// selects the event that was selected before at the same place on the
+// screen. When following, the newest event is selected.
+func (ui *UI) keepSelection(change func()) {
+	selected, hasSelection := ui.selectedEvent()
+	selectedRow, _ := ui.requestTable.GetSelection()
+	offset, _ := ui.requestTable.GetOffset()
+
+	change()
+
+	if len(ui.rows) == 0 {
+		return
+	}
+
+	if ui.follow {
+		newest := ui.newestRow()
+		ui.requestTable.Select(newest, 0)
+		if !hasSelection || ui.rows[newest-1].item.Path != selected.Path {
+			ui.viewSelected()
+		}
+		return
+	}
+
+	row := 1
+	if hasSelection {
+		for i, r := range ui.rows {
+			if r.item.Path == selected.Path {
+				row = i + 1
+				break
+			}
+		}
+	}
+	ui.requestTable.Select(row, 0)
+	if hasSelection {
+		ui.requestTable.SetOffset(max(offset+row-selectedRow, 0), 0)
+	}
+}
+
+// newestRow returns the table row of the most recently received event
+func (ui *UI) newestRow() int {
+	newest := 0
+	for i, row := range ui.rows {
+		if row.item.Time.After(ui.rows[newest].item.Time) {
+			newest = i
+		}
+	}
+	return newest + 1
+}
+
+// listedEvents returns the events in the order they're listed
+func (ui *UI) listedEvents() []storage.EventListItem {
+	events := make([]storage.EventListItem, len(ui.rows))
+	for i, row := range ui.rows {
+		events[i] = row.item
+	}
+	return events
+}
+
+// toggleFollow switches following the newest event on or off
+func (ui *UI) toggleFollow() *tcell.EventKey {
+	ui.setFollow(!ui.follow)
+	if ui.follow {
+		ui.renderTable()
+	}
+	return nil
+}
+
+func (ui *UI) setFollow(follow bool) {
+	ui.follow = follow
+	ui.updateListTitle()
+}
+
+// columnNames returns the names of the shown columns
+func (ui *UI) columnNames() []string {
+	names := make([]string, len(ui.columns))
+	for i, c := range ui.columns {
+		names[i] = c.name
+	}
+	return names
+}
+
+// cycleSort sorts by the next visible column
+func (ui *UI) cycleSort() *tcell.EventKey {
+	columns := ui.tableColumns()
+	next := 0
+	for i, c := range columns {
+		if c.name == ui.sortColumn {
+			next = (i + 1) % len(columns)
+		}
+	}
+
+	ui.sortColumn = columns[next].name
+	ui.renderTable()
+	ui.appendLog(fmt.Sprintf("Sorting by %s", columns[next].title))
+
+	return nil
+}
+
+func (ui *UI) reverseSort() *tcell.EventKey {
+	ui.sortDesc = !ui.sortDesc
+	ui.renderTable()
+	return nil
+}
+
+// showColumns lets columns be switched on and off, the choice is saved to the
+// configuration file
+func (ui *UI) showColumns() *tcell.EventKey {
+	list := tview.NewList()
+	list.SetTitle("Columns (ENTER: Show or hide)").SetBorder(true)
+
+	names := make([]string, 0, len(requestColumns))
+	for _, c := range requestColumns {
+		names = append(names, c.name)
+	}
+	// Expression columns only come from the config, keep them choosable
+	if ui.config != nil {
+		for _, name := range ui.config.UI.Columns {
+			if expr.IsExpr(name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	var render func()
+	render = func() {
+		current := list.GetCurrentItem()
+		list.Clear()
+
+		shown := map[string]bool{}
+		for _, c := range ui.tableColumns() {
+			shown[c.name] = true
+		}
+
+		for _, name := range names {
+			name := name
+			c, _ := column(name)
+			mark := "[ ]"
+			if shown[name] {
+				mark = "[x]"
+			}
+			list.AddItem(tview.Escape(mark)+" "+tview.Escape(c.title), "", 0, func() {
+				ui.toggleColumn(name)
+				render()
+			})
+		}
+		list.SetCurrentItem(current)
+	}
+	render()
+
+	ui.showModal(centered(list, 40, len(names)+2))
+	return nil
+}
+
+// toggleColumn shows or hides a column, shown columns go last, and saves
+// the columns to the config
+func (ui *UI) toggleColumn(name string) {
+	var columns []string
+	found := false
+	for _, c := range ui.tableColumns() {
+		if c.name == name {
+			found = true
+			continue
+		}
+		columns = append(columns, c.name)
+	}
+	if !found {
+		columns = append(columns, name)
+	}
+	if len(columns) == 0 {
+		ui.appendLog("At least one column has to be shown")
+		return
+	}
+
+	if ui.config == nil {
+		ui.config = &config.Config{}
+	}
+	ui.config.UI.Columns = columns
+	ui.columns = ui.tableColumns()
+	if err := config.Set("", []string{"ui", "columns"}, columns); err != nil {
+		ui.appendLog(fmt.Sprintf("Error saving columns: %v", err))
+	}
+
+	ui.renderTable()
+}
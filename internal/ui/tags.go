@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+)
+
+// tagNames returns every tag used in the current session
+func (ui *UI) tagNames() []string {
+	all, err := ui.store.AllTags()
+	if err != nil {
+		ui.appendLog(fmt.Sprintf("Error reading tags: %v", err))
+		return nil
+	}
+
+	unique := map[string]bool{}
+	for _, tags := range all {
+		for _, tag := range tags {
+			unique[tag] = true
+		}
+	}
+
+	names := make([]string, 0, len(unique))
+	for tag := range unique {
+		names = append(names, tag)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// selectedTags returns the tags of the selected event
+func (ui *UI) selectedTags() []string {
+	item, ok := ui.selectedEvent()
+	if !ok {
+		return nil
+	}
+
+	tags, err := ui.store.ForEvent(item.Path).Tags(item.Filename)
+	if err != nil {
+		ui.appendLog(fmt.Sprintf("Error reading tags: %v", err))
+		return nil
+	}
+	return tags
+}
+
+func (ui *UI) tagSelected(tag string) {
+	if tag == "" || !ui.writable("Tagging") {
+		return
+	}
+	item, ok := ui.selectedEvent()
+	if !ok {
+		return
+	}
+
+	if ui.setTags(item.Path, item.Filename, append(ui.selectedTags(), tag)) {
+		ui.appendLog(fmt.Sprintf("Tagged %s with %s", item.Filename, tag))
+	}
+}
+
+func (ui *UI) untagSelected(tag string) {
+	if !ui.writable("Tagging") {
+		return
+	}
+	item, ok := ui.selectedEvent()
+	if !ok {
+		return
+	}
+
+	var tags []string
+	for _, t := range ui.selectedTags() {
+		if t != tag {
+			tags = append(tags, t)
+		}
+	}
+	if ui.setTags(item.Path, item.Filename, tags) {
+		ui.appendLog(fmt.Sprintf("Removed the tag %s from %s", tag, item.Filename))
+	}
+}
+
+func (ui *UI) setTags(path, filename string, tags []string) bool {
+	if err := ui.store.ForEvent(path).SetTags(filename, tags); err != nil {
+		ui.appendLog(fmt.Sprintf("Error saving tags: %v", err))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// theme holds the colours of the primitives along with the accent used for
+// headers and the status bar, the background of the selected row and the
+// colours of successes, failures, marked rows and less important text
+type theme struct {
+	styles    tview.Theme
+	accent    tcell.Color
+	selection tcell.Color
+	good      tcell.Color
+	bad       tcell.Color
+	marked    tcell.Color
+	muted     tcell.Color
+	json      jsonColors
+}
+
+// jsonColors are the colour tags JSON is highlighted with
+type jsonColors struct {
+	key, str, number, boolean, null string
+}
+
+var themes = map[string]theme{
+	"dark": {
+		styles:    tview.Styles,
+		accent:    tcell.ColorYellow,
+		selection: tcell.ColorBlue,
+		good:      tcell.ColorGreen,
+		bad:       tcell.ColorRed,
+		marked:    tcell.ColorFuchsia,
+		muted:     tcell.ColorGray,
+		json: jsonColors{
+			key:     "#00ffff",
+			str:     "#87d787",
+			number:  "#ffaf5f",
+			boolean: "#d787ff",
+			null:    "#808080",
+		},
+	},
+	"light": {
+		styles: tview.Theme{
+			PrimitiveBackgroundColor:    tcell.ColorWhite,
+			ContrastBackgroundColor:     tcell.ColorLightSkyBlue,
+			MoreContrastBackgroundColor: tcell.ColorLightGreen,
+			BorderColor:                 tcell.ColorDarkGray,
+			TitleColor:                  tcell.ColorBlack,
+			GraphicsColor:               tcell.ColorDarkGray,
+			PrimaryTextColor:            tcell.ColorBlack,
+			SecondaryTextColor:          tcell.ColorNavy,
+			TertiaryTextColor:           tcell.ColorDarkGreen,
+			InverseTextColor:            tcell.ColorWhite,
+			ContrastSecondaryTextColor:  tcell.ColorNavy,
+		},
+		accent:    tcell.ColorNavy,
+		selection: tcell.ColorLightSkyBlue,
+		good:      tcell.ColorDarkGreen,
+		bad:       tcell.ColorDarkRed,
+		marked:    tcell.ColorPurple,
+		muted:     tcell.ColorDimGray,
+		json: jsonColors{
+			key:     "#005f87",
+			str:     "#005f00",
+			number:  "#af5f00",
+			boolean: "#870087",
+			null:    "#6c6c6c",
+		},
+	},
+}
+
+// colorTag is a colour as a colour tag
+func colorTag(color tcell.Color) string {
+	return fmt.Sprintf("#%06x", color.Hex())
+}
+
+// accentTag is the accent as a colour tag
+func (t theme) accentTag() string {
+	return colorTag(t.accent)
+}
+
+// accented wraps text, which has to be escaped already, in the accent colour
+func (t theme) accented(text string) string {
+	return fmt.Sprintf("[%s]%s[-]", t.accentTag(), text)
+}
+
+// field wraps a header or parameter name, which has to be escaped already,
+// in the colour of JSON keys
+func (t theme) field(text string) string {
+	return fmt.Sprintf("[%s]%s[-]", t.json.key, text)
+}
+
+// applyTheme sets the configured theme and colours, it has to run before
+// the primitives are created. It returns problems with the configuration,
+// which fall back to the dark theme's colours.
+func (ui *UI) applyTheme() []string {
+	var problems []string
+
+	name := "dark"
+	var colors map[string]string
+	if ui.config != nil {
+		if ui.config.UI.Theme != "" {
+			name = ui.config.UI.Theme
+		}
+		colors = ui.config.UI.Colors
+	}
+
+	t, ok := themes[name]
+	if !ok {
+		problems = append(problems, fmt.Sprintf("Unknown theme %s, use dark or light", name))
+		t = themes["dark"]
+	}
+
+	keys := make([]string, 0, len(colors))
+	for key := range colors {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		color := tcell.GetColor(colors[key])
+		if color == tcell.ColorDefault {
+			problems = append(problems, fmt.Sprintf("Unknown colour %s for %s in ui.colors", colors[key], key))
+			continue
+		}
+
+		switch key {
+		case "background":
+			t.styles.PrimitiveBackgroundColor = color
+		case "text":
+			t.styles.PrimaryTextColor = color
+		case "border":
+			t.styles.BorderColor = color
+		case "title":
+			t.styles.TitleColor = color
+		case "accent":
+			t.accent = color
+		case "selection":
+			t.selection = color
+		default:
+			problems = append(problems, fmt.Sprintf("Unknown colour %s in ui.colors, use background, text, border, title, accent or selection", key))
+		}
+	}
+
+	tview.Styles = t.styles
+	ui.theme = t
+	return problems
+}
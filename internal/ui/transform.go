@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/lukeberry99/whook/internal/transform"
+	"github.com/rivo/tview"
+)
+
+// previewTransform shows what the selected event looks like after its
+// service's transforms have run, without sending anything
+func (ui *UI) previewTransform() *tcell.EventKey {
+	item, ok := ui.selectedEvent()
+	if !ok {
+		return nil
+	}
+
+	serviceName, service := ui.eventService(item.ServiceName)
+
+	body, err := ui.store.ReadEventBody(item)
+	if err != nil {
+		ui.appendLog(fmt.Sprintf("Error reading event: %v", err))
+		return nil
+	}
+
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(true)
+	view.SetTitle("Transform Preview " + ui.theme.accented(fmt.Sprintf("(%s)", tview.Escape(serviceName)))).SetBorder(true)
+
+	pipeline, err := transform.New(service.Transforms)
+	if err != nil {
+		view.SetText(fmt.Sprintf("[red]%s[-]", tview.Escape(err.Error())))
+		ui.showModal(centered(view, 100, 30))
+		return nil
+	}
+
+	msg, err := pipeline.Apply(transform.Message{
+		URL:     service.ReplayURL,
+		Method:  http.MethodPost,
+		Headers: http.Header{"Content-Type": []string{"application/json"}},
+		Body:    body,
+	})
+	if err != nil {
+		view.SetText(fmt.Sprintf("[red]%s[-]", tview.Escape(err.Error())))
+		ui.showModal(centered(view, 100, 30))
+		return nil
+	}
+
+	view.SetText(ui.formatMessage(msg, len(service.Transforms)))
+	ui.showModal(centered(view, 100, 30))
+
+	return nil
+}
+
+func (ui *UI) formatMessage(msg transform.Message, steps int) string {
+	var b strings.Builder
+	if steps == 0 {
+		b.WriteString("[gray]No transforms configured for this service[-]\n\n")
+	}
+
+	url := msg.URL
+	if url == "" {
+		url = "(no replay_url)"
+	}
+	fmt.Fprintf(&b, "%s %s\n", ui.theme.accented(tview.Escape(msg.Method)), tview.Escape(url))
+
+	keys := make([]string, 0, len(msg.Headers))
+	for key := range msg.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s: %s\n", ui.theme.field(tview.Escape(key)), tview.Escape(strings.Join(msg.Headers[key], ", ")))
+	}
+	b.WriteString("\n")
+
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, msg.Body, "", "  "); err == nil {
+		b.WriteString(ui.theme.highlightJSON(pretty.String()))
+	} else {
+		b.WriteString(tview.Escape(string(msg.Body)))
+	}
+
+	return b.String()
+}
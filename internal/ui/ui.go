@@ -2,31 +2,67 @@ package ui
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/gdamore/tcell/v2"
 	"github.com/lukeberry99/whook/internal/config"
+	"github.com/lukeberry99/whook/internal/expr"
+	"github.com/lukeberry99/whook/internal/scheduler"
 	"github.com/lukeberry99/whook/internal/storage"
+	"github.com/lukeberry99/whook/internal/tunnel"
 	"github.com/rivo/tview"
 )
 
 type UI struct {
 	app             *tview.Application
-	requestList     *tview.List
+	screen          tcell.Screen
+	requestTable    *tview.Table
 	requestDetails  *tview.TextView
+	detailsTree     *tview.TreeView
+	details         *tview.Flex
+	detailTabBar    *tview.TextView
+	detailPages     *tview.Pages
+	bodyPages       *tview.Pages
+	detailViews     map[string]*tview.TextView
+	detailTab       string
+	showTree        bool
 	logView         *tview.TextView
+	responseView    *tview.TextView
 	statusBar       *tview.TextView
-	serviceModal    *tview.Modal
 	mainFlex        *tview.Flex
 	store           *storage.FileStorage
+	scheduler       *scheduler.Scheduler
+	tunnel          *tunnel.Status
+	dashboard       *dashboard
 	config          *config.Config
+	rows            []*requestRow
+	rowCache        map[string]*requestRow
+	columns         []requestColumn
+	follow          bool
+	marked          []storage.EventListItem
+	filter          *expr.Expr
+	sortColumn      string
+	sortDesc        bool
 	selectedService string
+	startedAt       time.Time
+	actions         []action
+	keymap          map[string]*action
+	theme           theme
+	read            map[string]bool
 	isModalVisible  bool
 }
 
-func New(cfg *config.Config, store *storage.FileStorage) *UI {
+func New(cfg *config.Config, store *storage.FileStorage, sched *scheduler.Scheduler, tunnelStatus *tunnel.Status) *UI {
 	ui := &UI{
-		app:    tview.NewApplication(),
-		store:  store,
-		config: cfg,
+		app:        tview.NewApplication(),
+		store:      store,
+		scheduler:  sched,
+		tunnel:     tunnelStatus,
+		startedAt:  time.Now(),
+		read:       map[string]bool{},
+		config:     cfg,
+		sortColumn: "time",
+		sortDesc:   true,
 	}
 
 	ui.selectedService = "All"
@@ -35,8 +71,10 @@ func New(cfg *config.Config, store *storage.FileStorage) *UI {
 	return ui
 }
 
-func StartUI(cfg *config.Config, logChan <-chan string, store *storage.FileStorage) error {
-	ui := New(cfg, store)
+// StartUI runs the UI until it's quit, tunnelStatus may be nil when no tunnel
+// is started
+func StartUI(cfg *config.Config, logChan <-chan string, store *storage.FileStorage, sched *scheduler.Scheduler, tunnelStatus *tunnel.Status) error {
+	ui := New(cfg, store, sched, tunnelStatus)
 	ui.initComponents()
 	ui.setupLayout()
 	ui.setupKeyBindings()
@@ -44,8 +82,6 @@ func StartUI(cfg *config.Config, logChan <-chan string, store *storage.FileStora
 	ui.watchFileUpdates()
 	ui.watchLogs(logChan)
 
-	ui.statusBar.SetText(" ESC: Quit | j/k/↑/↓: Navigate Services | ENTER: Select | TAB: Switch Panel | s: Select Service")
-
 	if err := ui.app.Run(); err != nil {
 		return fmt.Errorf("failed to start UI: %w", err)
 	}